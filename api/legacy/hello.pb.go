@@ -1,10 +1,17 @@
+// Unversioned control plane of the first LB builds
+//
+// Superseded by dc.v1 (api/dc/v1/control.proto). It is still served by
+// every LB, and used as a fallback for adjacent LBs without dc.v1, so that
+// old and new builds can be mixed during upgrades. The proto package stays
+// "main" because it is part of the method names on the wire.
+
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.11
-// 	protoc        v3.21.12
-// source: hello.proto
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: legacy/hello.proto
 
-package __
+package legacy
 
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
@@ -31,7 +38,7 @@ type BackendRequest struct {
 
 func (x *BackendRequest) Reset() {
 	*x = BackendRequest{}
-	mi := &file_hello_proto_msgTypes[0]
+	mi := &file_legacy_hello_proto_msgTypes[0]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -43,7 +50,7 @@ func (x *BackendRequest) String() string {
 func (*BackendRequest) ProtoMessage() {}
 
 func (x *BackendRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_hello_proto_msgTypes[0]
+	mi := &file_legacy_hello_proto_msgTypes[0]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -56,7 +63,7 @@ func (x *BackendRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BackendRequest.ProtoReflect.Descriptor instead.
 func (*BackendRequest) Descriptor() ([]byte, []int) {
-	return file_hello_proto_rawDescGZIP(), []int{0}
+	return file_legacy_hello_proto_rawDescGZIP(), []int{0}
 }
 
 func (x *BackendRequest) GetServerName() string {
@@ -76,7 +83,7 @@ type BackendStatus struct {
 
 func (x *BackendStatus) Reset() {
 	*x = BackendStatus{}
-	mi := &file_hello_proto_msgTypes[1]
+	mi := &file_legacy_hello_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -88,7 +95,7 @@ func (x *BackendStatus) String() string {
 func (*BackendStatus) ProtoMessage() {}
 
 func (x *BackendStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_hello_proto_msgTypes[1]
+	mi := &file_legacy_hello_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -101,7 +108,7 @@ func (x *BackendStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BackendStatus.ProtoReflect.Descriptor instead.
 func (*BackendStatus) Descriptor() ([]byte, []int) {
-	return file_hello_proto_rawDescGZIP(), []int{1}
+	return file_legacy_hello_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *BackendStatus) GetIsHealthy() bool {
@@ -122,7 +129,7 @@ type ControlMessage struct {
 
 func (x *ControlMessage) Reset() {
 	*x = ControlMessage{}
-	mi := &file_hello_proto_msgTypes[2]
+	mi := &file_legacy_hello_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -134,7 +141,7 @@ func (x *ControlMessage) String() string {
 func (*ControlMessage) ProtoMessage() {}
 
 func (x *ControlMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_hello_proto_msgTypes[2]
+	mi := &file_legacy_hello_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -147,7 +154,7 @@ func (x *ControlMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ControlMessage.ProtoReflect.Descriptor instead.
 func (*ControlMessage) Descriptor() ([]byte, []int) {
-	return file_hello_proto_rawDescGZIP(), []int{2}
+	return file_legacy_hello_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *ControlMessage) GetCommand() string {
@@ -175,7 +182,7 @@ type ControlResponse struct {
 
 func (x *ControlResponse) Reset() {
 	*x = ControlResponse{}
-	mi := &file_hello_proto_msgTypes[3]
+	mi := &file_legacy_hello_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -187,7 +194,7 @@ func (x *ControlResponse) String() string {
 func (*ControlResponse) ProtoMessage() {}
 
 func (x *ControlResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_hello_proto_msgTypes[3]
+	mi := &file_legacy_hello_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -200,7 +207,7 @@ func (x *ControlResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ControlResponse.ProtoReflect.Descriptor instead.
 func (*ControlResponse) Descriptor() ([]byte, []int) {
-	return file_hello_proto_rawDescGZIP(), []int{3}
+	return file_legacy_hello_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *ControlResponse) GetStatus() string {
@@ -217,47 +224,60 @@ func (x *ControlResponse) GetPayload() int64 {
 	return 0
 }
 
-var File_hello_proto protoreflect.FileDescriptor
-
-const file_hello_proto_rawDesc = "" +
-	"\n" +
-	"\vhello.proto\x12\x04main\"1\n" +
-	"\x0eBackendRequest\x12\x1f\n" +
-	"\vserver_name\x18\x01 \x01(\tR\n" +
-	"serverName\".\n" +
-	"\rBackendStatus\x12\x1d\n" +
-	"\n" +
-	"is_healthy\x18\x01 \x01(\bR\tisHealthy\"D\n" +
-	"\x0eControlMessage\x12\x18\n" +
-	"\acommand\x18\x01 \x01(\tR\acommand\x12\x18\n" +
-	"\apayload\x18\x02 \x01(\x03R\apayload\"C\n" +
-	"\x0fControlResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
-	"\apayload\x18\x02 \x01(\x03R\apayload2\x8f\x01\n" +
-	"\fLoadBalancer\x12=\n" +
-	"\x10GetBackendStatus\x12\x14.main.BackendRequest\x1a\x13.main.BackendStatus\x12@\n" +
-	"\rControlStream\x12\x14.main.ControlMessage\x1a\x15.main.ControlResponse(\x010\x01B\x03Z\x01.b\x06proto3"
+var File_legacy_hello_proto protoreflect.FileDescriptor
+
+var file_legacy_hello_proto_rawDesc = string([]byte{
+	0x0a, 0x12, 0x6c, 0x65, 0x67, 0x61, 0x63, 0x79, 0x2f, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x6d, 0x61, 0x69, 0x6e, 0x22, 0x31, 0x0a, 0x0e, 0x42, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x2e, 0x0a,
+	0x0d, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1d,
+	0x0a, 0x0a, 0x69, 0x73, 0x5f, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x09, 0x69, 0x73, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x22, 0x44, 0x0a,
+	0x0e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79,
+	0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c,
+	0x6f, 0x61, 0x64, 0x22, 0x43, 0x0a, 0x0f, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18,
+	0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x32, 0x8f, 0x01, 0x0a, 0x0c, 0x4c, 0x6f, 0x61,
+	0x64, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x72, 0x12, 0x3d, 0x0a, 0x10, 0x47, 0x65, 0x74,
+	0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x14, 0x2e,
+	0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x40, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x74,
+	0x72, 0x6f, 0x6c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x14, 0x2e, 0x6d, 0x61, 0x69, 0x6e,
+	0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a,
+	0x15, 0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x26, 0x5a, 0x24, 0x63, 0x75,
+	0x73, 0x74, 0x6f, 0x6d, 0x65, 0x5f, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x65, 0x64, 0x52, 0x52,
+	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x6c, 0x65, 0x67, 0x61, 0x63, 0x79, 0x3b, 0x6c, 0x65, 0x67, 0x61,
+	0x63, 0x79, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
 
 var (
-	file_hello_proto_rawDescOnce sync.Once
-	file_hello_proto_rawDescData []byte
+	file_legacy_hello_proto_rawDescOnce sync.Once
+	file_legacy_hello_proto_rawDescData []byte
 )
 
-func file_hello_proto_rawDescGZIP() []byte {
-	file_hello_proto_rawDescOnce.Do(func() {
-		file_hello_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_hello_proto_rawDesc), len(file_hello_proto_rawDesc)))
+func file_legacy_hello_proto_rawDescGZIP() []byte {
+	file_legacy_hello_proto_rawDescOnce.Do(func() {
+		file_legacy_hello_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_legacy_hello_proto_rawDesc), len(file_legacy_hello_proto_rawDesc)))
 	})
-	return file_hello_proto_rawDescData
+	return file_legacy_hello_proto_rawDescData
 }
 
-var file_hello_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
-var file_hello_proto_goTypes = []any{
+var file_legacy_hello_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_legacy_hello_proto_goTypes = []any{
 	(*BackendRequest)(nil),  // 0: main.BackendRequest
 	(*BackendStatus)(nil),   // 1: main.BackendStatus
 	(*ControlMessage)(nil),  // 2: main.ControlMessage
 	(*ControlResponse)(nil), // 3: main.ControlResponse
 }
-var file_hello_proto_depIdxs = []int32{
+var file_legacy_hello_proto_depIdxs = []int32{
 	0, // 0: main.LoadBalancer.GetBackendStatus:input_type -> main.BackendRequest
 	2, // 1: main.LoadBalancer.ControlStream:input_type -> main.ControlMessage
 	1, // 2: main.LoadBalancer.GetBackendStatus:output_type -> main.BackendStatus
@@ -269,26 +289,26 @@ var file_hello_proto_depIdxs = []int32{
 	0, // [0:0] is the sub-list for field type_name
 }
 
-func init() { file_hello_proto_init() }
-func file_hello_proto_init() {
-	if File_hello_proto != nil {
+func init() { file_legacy_hello_proto_init() }
+func file_legacy_hello_proto_init() {
+	if File_legacy_hello_proto != nil {
 		return
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: unsafe.Slice(unsafe.StringData(file_hello_proto_rawDesc), len(file_hello_proto_rawDesc)),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_legacy_hello_proto_rawDesc), len(file_legacy_hello_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
-		GoTypes:           file_hello_proto_goTypes,
-		DependencyIndexes: file_hello_proto_depIdxs,
-		MessageInfos:      file_hello_proto_msgTypes,
+		GoTypes:           file_legacy_hello_proto_goTypes,
+		DependencyIndexes: file_legacy_hello_proto_depIdxs,
+		MessageInfos:      file_legacy_hello_proto_msgTypes,
 	}.Build()
-	File_hello_proto = out.File
-	file_hello_proto_goTypes = nil
-	file_hello_proto_depIdxs = nil
+	File_legacy_hello_proto = out.File
+	file_legacy_hello_proto_goTypes = nil
+	file_legacy_hello_proto_depIdxs = nil
 }
@@ -1,10 +1,10 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.6.0
-// - protoc             v3.21.12
-// source: hello.proto
+// - protoc             (unknown)
+// source: legacy/hello.proto
 
-package __
+package legacy
 
 import (
 	context "context"
@@ -154,5 +154,5 @@ var LoadBalancer_ServiceDesc = grpc.ServiceDesc{
 			ClientStreams: true,
 		},
 	},
-	Metadata: "hello.proto",
+	Metadata: "legacy/hello.proto",
 }
@@ -51,6 +51,12 @@ echo ${cls[@]} ${web[@]}
 python3 ../tools/adjacentListController.py $nw_model ${cls[@]} ${web[@]}
 echo "Created adjacentList per cluster"
 
+# check the topology before building and running the LBs
+if ! go run ../tools/topocheck -file ../json/adjacentList.json; then
+  echo "Invalid adjacentList.json. Fix the topology and retry."
+  exit 1
+fi
+
 # set delay between clusters (manual setting)
 python3 ../tools/delayController.py
 echo "Set delay between clusters"
@@ -73,33 +79,89 @@ echo "Target URL: $url"
 
 echo "-------- URL OK --------"
 
-# set target file to apply
-read -p "file to apply [t: DC(threshold), d: DC(diff), r: RR, l: LC]: " file
+# set forwarding policy to apply
+read -p "policy to apply [t: DC(threshold), d: DC(diff), f: DC(flow), s: DC(sos), e: DC(latency), x: DX, g: Gradient, m: Mean, i: PID, q: M/M/c, c: Central, r: RR, l: LC, u: Random, p: Static, j: JSQ(d), o: Local]: " file
 case "$file" in
   t)
-    apply_file="lb_thre.go"
+    policy="threshold"
+    label="lb_thre"
     ;;
   d)
-    apply_file="lb_diff.go"
+    policy="diff"
+    label="lb_diff"
+    ;;
+  f)
+    policy="flow"
+    label="lb_flow"
+    ;;
+  s)
+    policy="sos"
+    label="lb_sos"
+    ;;
+  e)
+    policy="latency"
+    label="lb_latency"
+    ;;
+  x)
+    policy="dx"
+    label="lb_dx"
+    ;;
+  g)
+    policy="gradient"
+    label="lb_gradient"
+    ;;
+  m)
+    policy="mean"
+    label="lb_mean"
+    ;;
+  i)
+    policy="pid"
+    label="lb_pid"
+    ;;
+  q)
+    policy="mmc"
+    label="lb_mmc"
+    ;;
+  c)
+    policy="central"
+    label="lb_central"
     ;;
   r)
-    apply_file="lb_rr.go"
+    policy="rr"
+    label="lb_rr"
     ;;
   l)
-    apply_file="lb_lc.go"
+    policy="lc"
+    label="lb_lc"
+    ;;
+  u)
+    policy="random"
+    label="lb_random"
+    ;;
+  p)
+    policy="static"
+    label="lb_static"
+    ;;
+  j)
+    policy="jsq"
+    label="lb_jsq"
+    ;;
+  o)
+    policy="local"
+    label="lb_local"
     ;;
   *)
-    echo "Invalid input. Please enter one of: t, d, r, l"
+    echo "Invalid input. Please enter one of: t, d, f, s, e, x, g, m, i, q, c, r, l, u, p, j, o"
     exit 1
     ;;
 esac
 
-compiled_file="${apply_file%.go}"
+compiled_file="lb"
 
-echo $apply_file $compiled_file
+echo $policy $label
 
 # create directory for saving measurement results
-dirname="${compiled_file}_t${feedback}_t${threshold}_k${safe_kappa}_vus${vus}"
+dirname="${label}_t${feedback}_t${threshold}_k${safe_kappa}_vus${vus}"
 data_dir="../data/implement/${dirname}"
 mkdir -p "$data_dir"
 
@@ -111,9 +173,14 @@ for count in $(seq 0 "$KEY");
 do 
     docker exec Cluster${count}_LB sh -c 'echo "nameserver 8.8.8.8" > /etc/resolv.conf'
     docker exec Cluster${count}_LB cat /etc/resolv.conf
-    docker exec Cluster${count}_LB sh -c "go build -o compiled/$compiled_file lb/$apply_file"
+    docker exec Cluster${count}_LB sh -c "go build -o compiled/$compiled_file ./lb"
 done
 
+# the central policy needs the controller on the host
+if [ "$policy" = "central" ]; then
+    go build -o ../compiled/controller ../controller || exit 1
+fi
+
 echo "-------- Build OK --------"
 
 for count in $(seq 0 "$KEY");
@@ -131,7 +198,7 @@ do
 
     for count in $(seq 0 "$KEY");
     do
-        docker exec -d Cluster${count}_LB compiled/$compiled_file $cluster -t $feedback -q $threshold -k $kappa /bin/bash
+        docker exec -d Cluster${count}_LB compiled/$compiled_file $cluster -node-id cluster${count} -leader cluster${cluster} -policy $policy -t $feedback -q $threshold -k $kappa /bin/bash
         docker exec Cluster${count}_LB ps aux # goのプロセスが走っていなかったらやり直しにしたい
     done
 
@@ -148,8 +215,19 @@ do
       sleep 1
     done
 
+    if [ "$policy" = "central" ]; then
+      timestamp=$(date +"%Y%m%d_%H%M%S")
+      ../compiled/controller -adjacency-file ../json/adjacentList.json -interval $feedback -log-file "${data_dir}/controller_${attempt_count}_${timestamp}.csv" &
+      controller_pid=$!
+    fi
+
     # load test using apache jmeter
     ./../tools/jmeter_multi.sh $url $time $vus $KEY
+    # stop the controller first, wait would not return while it runs
+    if [ -n "$controller_pid" ]; then
+      kill $controller_pid
+      controller_pid=""
+    fi
     wait
     echo "All tests completed."
 
@@ -185,6 +263,7 @@ timestamp=$(date +"%Y%m%d_%H%M%S")
 {
 echo "Experiment in these parameters is finished"
 echo "feedback: $feedback [ms]"
+echo "policy: $policy"
 echo "threshold: $threshold"
 echo "kappa: $kappa"
 echo "virtual users: $vus [users]"
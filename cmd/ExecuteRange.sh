@@ -7,7 +7,7 @@ run_experiment() {
   local time=60
   local attempt_count=1
 
-  local dirname="${compiled_file}_t${threshold}_k${safe_kappa}_vus${vus}"
+  local dirname="${label}_t${threshold}_k${safe_kappa}_vus${vus}"
   local data_dir="../data/implement/${dirname}"
   mkdir -p "$data_dir"
 
@@ -19,7 +19,7 @@ run_experiment() {
 
     # LB execution
     for count in $(seq 0 "$KEY"); do
-      docker exec -d Cluster${count}_LB compiled/$compiled_file $cluster -t $feedback -q $threshold -k $kappa
+      docker exec -d Cluster${count}_LB compiled/$compiled_file $cluster -node-id cluster${count} -leader cluster${cluster} -policy $policy -t $feedback -q $threshold -k $kappa
       docker exec Cluster${count}_LB ps aux
     done
 
@@ -34,8 +34,19 @@ run_experiment() {
       sleep 1
     done
 
+    if [ "$policy" = "central" ]; then
+      timestamp=$(date +"%Y%m%d_%H%M%S")
+      ../compiled/controller -adjacency-file ../json/adjacentList.json -interval $feedback -log-file "${data_dir}/controller_${attempt_count}_${timestamp}.csv" &
+      controller_pid=$!
+    fi
+
     # JMeter execution
     ./../tools/jmeter_multi.sh $url $time $vus $KEY
+    # stop the controller first, wait would not return while it runs
+    if [ -n "$controller_pid" ]; then
+      kill $controller_pid
+      controller_pid=""
+    fi
     wait
     echo "All tests completed."
 
@@ -68,6 +79,7 @@ run_experiment() {
   {
     echo "Experiment in these parameters is finished"
     echo "feedback: $feedback [ms]"
+    echo "policy: $policy"
     echo "threshold: $threshold"
     echo "kappa: $kappa"
     echo "virtual users: $vus [users]"
@@ -147,34 +159,53 @@ fi
 
 echo $threshold_values $kappa_values
 
-# set target file to apply
+# set forwarding policy to apply
 flag=0
 if [[ ${#kappa_values[@]} -gt 1 ]]; then
-  apply_file="lb_diff.go"
-  echo "Selected lb_diff.go"
+  policy="diff"; label="lb_diff"
+  echo "Selected policy diff"
   flag=1
 elif [[ ${#threshold_values[@]} -gt 1 ]]; then
-  apply_file="lb_rr.go"
-  echo "Selected lb_rr.go"
+  policy="rr"; label="lb_rr"
+  echo "Selected policy rr"
   flag=2
 else
-  read -p "file to apply [t: DC(threshold), d: DC(diff), r: RR, l: LC]: " file
+  read -p "policy to apply [t: DC(threshold), d: DC(diff), f: DC(flow), s: DC(sos), e: DC(latency), x: DX, g: Gradient, m: Mean, i: PID, q: M/M/c, c: Central, r: RR, l: LC, u: Random, p: Static, j: JSQ(d), o: Local]: " file
   case "$file" in
-    t) apply_file="lb_thre.go" ;;
-    d) apply_file="lb_diff.go" ;;
-    r) apply_file="lb_rr.go" ;;
-    l) apply_file="lb_lc.go" ;;
+    t) policy="threshold"; label="lb_thre" ;;
+    d) policy="diff"; label="lb_diff" ;;
+    f) policy="flow"; label="lb_flow" ;;
+    s) policy="sos"; label="lb_sos" ;;
+    e) policy="latency"; label="lb_latency" ;;
+    x) policy="dx"; label="lb_dx" ;;
+    g) policy="gradient"; label="lb_gradient" ;;
+    m) policy="mean"; label="lb_mean" ;;
+    i) policy="pid"; label="lb_pid" ;;
+    q) policy="mmc"; label="lb_mmc" ;;
+    c) policy="central"; label="lb_central" ;;
+    r) policy="rr"; label="lb_rr" ;;
+    l) policy="lc"; label="lb_lc" ;;
+    u) policy="random"; label="lb_random" ;;
+    p) policy="static"; label="lb_static" ;;
+    j) policy="jsq"; label="lb_jsq" ;;
+    o) policy="local"; label="lb_local" ;;
     *) echo "Invalid input."; exit 1 ;;
   esac
 fi
 
-compiled_file="${apply_file%.go}"
-echo $apply_file $compiled_file
+compiled_file="lb"
+echo $policy $label
 
 # create adjacency list
 python3 ../tools/adjacentListController.py $nw_model ${cls[@]} ${web[@]}
 echo "Created adjacentList per cluster"
 
+# check the topology before building and running the LBs
+if ! go run ../tools/topocheck -file ../json/adjacentList.json; then
+  echo "Invalid adjacentList.json. Fix the topology and retry."
+  exit 1
+fi
+
 # set delay between clusters (manual setting)
 python3 ../tools/delayController.py
 echo "Set delay between clusters"
@@ -187,9 +218,14 @@ do
     echo "== Cluster${count}_LB =="
     docker exec Cluster${count}_LB sh -c 'echo "nameserver 8.8.8.8" > /etc/resolv.conf'
     docker exec Cluster${count}_LB cat /etc/resolv.conf
-    docker exec Cluster${count}_LB sh -c "go build -o compiled/$compiled_file lb/$apply_file"
+    docker exec Cluster${count}_LB sh -c "go build -o compiled/$compiled_file ./lb"
 done
 
+# the central policy needs the controller on the host
+if [ "$policy" = "central" ]; then
+    go build -o ../compiled/controller ../controller || exit 1
+fi
+
 echo "-------- Build OK --------"
 
 for count in $(seq 0 "$KEY");
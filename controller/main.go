@@ -0,0 +1,321 @@
+// Central controller: a comparison baseline for DC with a global view
+//
+// Usage: go run ./controller [-adjacency-file ./json/adjacentList.json] [-interval 100]
+//
+//	[-log-file ./log/controller.csv]
+//
+// Every interval the controller takes the load report of every LB (dc.v1
+// Snapshot) and computes the transfers over the edges of the adjacency list
+// that minimize the maximum load: every cluster ends at the same load per
+// capacity L* = sum(queue) / sum(capacity). Among the flows that achieve it,
+// the one with the least cost-weighted squared transfer is taken, the
+// electrical flow f_ij = (phi_i - phi_j) / cost_ij with the potentials
+// L_W phi = queue - capacity L* (L_W: Laplacian with the edge weights
+// 1/cost). The flows are pushed to the LBs as the weights of their adjacent
+// LBs (dc.v1 SetWeights), which the LBs started with -policy central forward
+// with. Clusters whose LB does not answer are left out of the round, and
+// every connected component of the rest is balanced on its own.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	dcv1 "custome_weightedRR/api/dc/v1"
+	"custome_weightedRR/topology"
+)
+
+const controllerName = "controller"
+
+// Load of one cluster in a round
+type load struct {
+	queue    float64
+	capacity float64
+}
+
+func main() {
+	var file, logFile string
+	var interval int
+	var ports topology.Ports
+
+	flag.StringVar(&file, "adjacency-file", "./json/adjacentList.json", "adjacency list")
+	flag.IntVar(&interval, "interval", 100, "control interval [ms]")
+	flag.StringVar(&logFile, "log-file", "./log/controller.csv", "CSV of the loads and flows of every round (empty: none)")
+	flag.IntVar(&ports.HTTP, "http-port", 8001, "default port of the LBs receiving requests")
+	flag.IntVar(&ports.GRPC, "grpc-port", 50051, "default port of the LBs serving dc.v1")
+	flag.IntVar(&ports.Backend, "backend-port", 80, "default port of the web servers")
+	flag.Parse()
+
+	if interval <= 0 {
+		fmt.Fprintln(os.Stderr, "-interval must be positive")
+		os.Exit(2)
+	}
+
+	topo, err := topology.Load(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	report := topology.Check(topo, ports)
+	if report.String() != "" {
+		fmt.Fprint(os.Stderr, report)
+	}
+	if err := report.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	ids := topo.IDs()
+	clients := make(map[string]dcv1.DiffusionControlClient, len(ids))
+	for _, id := range ids {
+		addr, err := topo.GRPCAddr(id, ports)
+		if err != nil {
+			log.Fatalf("%s: %v", id, err)
+		}
+		conn, err := grpc.Dial(addr, grpc.WithInsecure())
+		if err != nil {
+			log.Fatalf("Failed to connect to %s at %s: %v", id, addr, err)
+		}
+		defer conn.Close()
+		clients[id] = dcv1.NewDiffusionControlClient(conn)
+	}
+
+	var out *csvLog
+	if logFile != "" {
+		if out, err = newCSVLog(logFile, topo); err != nil {
+			log.Fatal(err)
+		}
+		defer out.close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	period := time.Duration(interval) * time.Millisecond
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	log.Printf("Controlling %d LBs every %v", len(ids), period)
+
+	start := time.Now()
+	for round := uint64(1); ; round++ {
+		loads := snapshot(ctx, clients, period)
+		flows := optimalFlows(topo, loads)
+		applied := push(ctx, clients, loads, flows, round, period)
+		if out != nil {
+			out.write(time.Since(start), round, loads, flows, applied)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopped after %d rounds", round)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reports of all LBs that answer within timeout
+func snapshot(ctx context.Context, clients map[string]dcv1.DiffusionControlClient, timeout time.Duration) map[string]load {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	loads := make(map[string]load, len(clients))
+	for id, client := range clients {
+		wg.Add(1)
+		go func(id string, client dcv1.DiffusionControlClient) {
+			defer wg.Done()
+			sctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			r, err := client.Snapshot(sctx, &dcv1.SnapshotRequest{Controller: controllerName})
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			loads[id] = load{queue: float64(r.Queue), capacity: r.Capacity}
+			mu.Unlock()
+		}(id, client)
+	}
+	wg.Wait()
+	return loads
+}
+
+// Min-max load flows between the clusters in loads, keyed by the ordered
+// pair [from, to] with a positive flow [requests]
+func optimalFlows(topo topology.Topology, loads map[string]load) map[[2]string]float64 {
+	reached := make(topology.Topology, len(loads))
+	for id := range loads {
+		reached[id] = topo[id]
+	}
+
+	flows := make(map[[2]string]float64)
+	cost := func(a, b string) float64 { return topo[a].AdjacentList[b].CostOrDefault() }
+	for _, component := range reached.Components() {
+		sub := make(topology.Topology, len(component))
+		queue, capacity := 0.0, 0.0
+		for _, id := range component {
+			sub[id] = topo[id]
+			queue += loads[id].queue
+			capacity += loads[id].capacity
+		}
+		if len(component) < 2 || capacity <= 0 {
+			continue
+		}
+
+		level := queue / capacity
+		ids, l := topology.Laplacian(sub, func(a, b string) float64 { return 1 / cost(a, b) })
+		excess := make([]float64, len(ids))
+		for i, id := range ids {
+			excess[i] = loads[id].queue - loads[id].capacity*level
+		}
+		phi := topology.SolveLaplacian(l, excess)
+
+		index := make(map[string]int, len(ids))
+		for i, id := range ids {
+			index[id] = i
+		}
+		for _, a := range ids {
+			for b := range sub[a].AdjacentList {
+				j, ok := index[b]
+				if !ok || !topology.LessID(a, b) {
+					continue
+				}
+				f := (phi[index[a]] - phi[j]) / cost(a, b)
+				if f > 0 {
+					flows[[2]string{a, b}] = f
+				} else if f < 0 {
+					flows[[2]string{b, a}] = -f
+				}
+			}
+		}
+	}
+	return flows
+}
+
+// Push the flows out of every reached LB as the weights of its adjacent LBs;
+// returns the LBs that applied them
+func push(ctx context.Context, clients map[string]dcv1.DiffusionControlClient, loads map[string]load, flows map[[2]string]float64, round uint64, timeout time.Duration) map[string]bool {
+	weights := make(map[string][]*dcv1.NeighborWeight, len(loads))
+	for edge, f := range flows {
+		if w := int64(math.Round(f)); w > 0 {
+			weights[edge[0]] = append(weights[edge[0]], &dcv1.NeighborWeight{NodeId: edge[1], Weight: w, Flow: f})
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	applied := make(map[string]bool, len(loads))
+	for id := range loads {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			sctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			res, err := clients[id].SetWeights(sctx, &dcv1.SetWeightsRequest{Weights: weights[id], Round: round})
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			applied[id] = res.Applied
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	for id, ok := range applied {
+		if !ok && round == 1 {
+			log.Printf("Warning: %s does not apply the weights (start it with -policy central)", id)
+		}
+	}
+	return applied
+}
+
+// CSV of every round: the load of every cluster and the flow of every
+// directed edge; clusters that did not answer are left empty
+type csvLog struct {
+	file  *os.File
+	w     *bufio.Writer
+	ids   []string
+	edges [][2]string
+}
+
+func newCSVLog(path string, topo topology.Topology) (*csvLog, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	c := &csvLog{file: file, w: bufio.NewWriter(file), ids: topo.IDs()}
+	for _, a := range c.ids {
+		for _, b := range c.ids {
+			if _, ok := topo[a].AdjacentList[b]; ok {
+				c.edges = append(c.edges, [2]string{a, b})
+			}
+		}
+	}
+
+	header := []string{"Time", "Round", "Reached", "Level", "MaxLoad"}
+	for _, id := range c.ids {
+		header = append(header, id+"_Queue", id+"_Capacity", id+"_Applied")
+	}
+	for _, e := range c.edges {
+		header = append(header, fmt.Sprintf("%s_%s_Flow", e[0], e[1]))
+	}
+	c.w.WriteString(strings.Join(header, ",") + "\n")
+	return c, nil
+}
+
+func (c *csvLog) write(elapsed time.Duration, round uint64, loads map[string]load, flows map[[2]string]float64, applied map[string]bool) {
+	queue, capacity, maxLoad := 0.0, 0.0, 0.0
+	for _, l := range loads {
+		queue += l.queue
+		capacity += l.capacity
+		if l.capacity > 0 {
+			maxLoad = max(maxLoad, l.queue/l.capacity)
+		}
+	}
+	level := 0.0
+	if capacity > 0 {
+		level = queue / capacity
+	}
+
+	record := []string{
+		formatFloat(float64(elapsed) / float64(time.Millisecond)),
+		strconv.FormatUint(round, 10),
+		strconv.Itoa(len(loads)),
+		formatFloat(level),
+		formatFloat(maxLoad),
+	}
+	for _, id := range c.ids {
+		l, ok := loads[id]
+		if !ok {
+			record = append(record, "", "", "")
+			continue
+		}
+		record = append(record, formatFloat(l.queue), formatFloat(l.capacity), strconv.FormatBool(applied[id]))
+	}
+	for _, e := range c.edges {
+		record = append(record, formatFloat(flows[e]))
+	}
+	c.w.WriteString(strings.Join(record, ",") + "\n")
+	c.w.Flush()
+}
+
+func (c *csvLog) close() {
+	c.w.Flush()
+	c.file.Close()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
@@ -9,6 +9,7 @@ require (
 	github.com/redis/go-redis/v9 v9.7.3
 	google.golang.org/grpc v1.66.2
 	google.golang.org/protobuf v1.36.5
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
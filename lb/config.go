@@ -0,0 +1,454 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"custome_weightedRR/topology"
+)
+
+// Config holds every setting of the LB.
+// Values are resolved in the order: defaults < config file < LB_* environment variables < flags
+type Config struct {
+	NodeID         string  `json:"node_id" yaml:"node_id"`                 // Own node ID in the adjacency list
+	Leader         string  `json:"leader" yaml:"leader"`                   // Node ID of the coordinator (default: "cluster<cluster>")
+	Cluster        int     `json:"cluster" yaml:"cluster"`                 // Target cluster of flash crowds
+	Policy         string  `json:"policy" yaml:"policy"`                   // Forwarding policy
+	Feedback       int     `json:"feedback" yaml:"feedback"`               // Feedback interval between adjacent LBs [ms]
+	Threshold      int     `json:"threshold" yaml:"threshold"`             // Threshold of forwarding
+	Kappa          float64 `json:"kappa" yaml:"kappa"`                     // Diffusion coefficient
+	RedisAddr      string  `json:"redis_addr" yaml:"redis_addr"`           // Redis used for the start synchronization
+	HTTPPort       int     `json:"http_port" yaml:"http_port"`             // Requests from users and adjacent LBs
+	DataPort       int     `json:"data_port" yaml:"data_port"`             // CSV export of the measurement results
+	GRPCPort       int     `json:"grpc_port" yaml:"grpc_port"`             // Feedback between adjacent LBs
+	MetricsPort    int     `json:"metrics_port" yaml:"metrics_port"`       // Prometheus exporter
+	BackendPort    int     `json:"backend_port" yaml:"backend_port"`       // Web servers in the cluster
+	AdjacencyFile  string  `json:"adjacency_file" yaml:"adjacency_file"`   // Output of tools/adjacentListController.py
+	LogFile        string  `json:"log_file" yaml:"log_file"`               // CSV written by the data receiver
+	SampleInterval int     `json:"sample_interval" yaml:"sample_interval"` // Interval of the evaluation parameters [ms]
+	WatchInterval  int     `json:"watch_interval" yaml:"watch_interval"`   // Polling interval of the adjacency list [ms], 0 disables
+	SuspectAfter   int     `json:"suspect_after" yaml:"suspect_after"`     // Unanswered feedback rounds before a neighbor is suspect
+	DownAfter      int     `json:"down_after" yaml:"down_after"`           // Unanswered feedback rounds before a neighbor is down
+	RecoverAfter   int     `json:"recover_after" yaml:"recover_after"`     // Answered feedback rounds before a suspect neighbor is healthy
+	ReconnectMin   int     `json:"reconnect_min" yaml:"reconnect_min"`     // First reconnection backoff [ms]
+	ReconnectMax   int     `json:"reconnect_max" yaml:"reconnect_max"`     // Maximum reconnection backoff [ms]
+	Keepalive      int     `json:"keepalive" yaml:"keepalive"`             // gRPC keepalive interval [ms], 0 disables
+	LegacyAPI      bool    `json:"legacy_api" yaml:"legacy_api"`           // Serve and fall back to the API of the builds before dc.v1
+	Capacity       float64 `json:"capacity" yaml:"capacity"`               // Capacity of the web servers advertised to the neighbors, 0 uses their number
+	EWMAAlpha      float64 `json:"ewma_alpha" yaml:"ewma_alpha"`           // Smoothing factor of the arrival rate and the latency
+	Normalize      string  `json:"normalize" yaml:"normalize"`             // Load compared by DC: queue (none), per capacity or per service rate
+	KappaMode      string  `json:"kappa_mode" yaml:"kappa_mode"`           // Diffusion coefficient of each edge: kappa (global) or from the degrees (metropolis)
+	Beta           float64 `json:"beta" yaml:"beta"`                       // Momentum of the second-order diffusion (sos policy), 1 is first-order
+	GradientLow    int     `json:"gradient_low" yaml:"gradient_low"`       // Queue at or below which a cluster is lightly loaded (gradient policy)
+	GossipEpoch    int     `json:"gossip_epoch" yaml:"gossip_epoch"`       // Restart interval of the gossip of the global mean load [ms], 0 disables (except for the mean policy)
+	MeanMargin     float64 `json:"mean_margin" yaml:"mean_margin"`         // Sessions above the global mean before forwarding (mean policy)
+	JSQD           int     `json:"jsq_d" yaml:"jsq_d"`                     // Adjacent LBs sampled per request (jsq policy)
+	PIDKp          float64 `json:"pid_kp" yaml:"pid_kp"`                   // Proportional gain (pid policy)
+	PIDKi          float64 `json:"pid_ki" yaml:"pid_ki"`                   // Integral gain [1/s] (pid policy)
+	PIDKd          float64 `json:"pid_kd" yaml:"pid_kd"`                   // Derivative gain [s] (pid policy)
+	PIDMax         float64 `json:"pid_max" yaml:"pid_max"`                 // Largest flow to a neighbor [requests per feedback interval], 0 unlimited (pid policy)
+	RTTCost        string  `json:"rtt_cost" yaml:"rtt_cost"`               // Discount of the flow by the round trip: linear, exp or cutoff (latency policy)
+	RTTScale       int     `json:"rtt_scale" yaml:"rtt_scale"`             // Round trip of the cost function [ms] (latency policy)
+	Estimator      string  `json:"estimator" yaml:"estimator"`             // Load of the neighbors between reports: last reported or extrapolated (trend)
+	EstimatorAlpha float64 `json:"estimator_alpha" yaml:"estimator_alpha"` // Gain of the trend estimator (0-1]
+	StaleAfter     int     `json:"stale_after" yaml:"stale_after"`         // Age of a report beyond which it is discounted [ms], 0 is the suspect timeout
+	CorrectTransit bool    `json:"correct_transit" yaml:"correct_transit"` // Count the requests forwarded since the last report into the neighbor load and weight
+	DeadBand       int     `json:"dead_band" yaml:"dead_band"`             // Forwarding over a threshold stops only at the threshold minus this [sessions]
+	Dwell          int     `json:"dwell" yaml:"dwell"`                     // Minimum time between two changes of the forwarding mode [ms]
+	Smoothing      int     `json:"smoothing" yaml:"smoothing"`             // Time constant of the signal compared with the threshold [ms], 0 disables
+}
+
+// Values of kappa_mode
+const (
+	kappaGlobal     = "global"
+	kappaMetropolis = "metropolis"
+)
+
+// Values of normalize
+const (
+	normalizeNone     = "none"
+	normalizeCapacity = "capacity"
+	normalizeRate     = "rate"
+)
+
+func defaultConfig() Config {
+	return Config{
+		NodeID:         "",
+		Leader:         "",
+		Cluster:        0,
+		Policy:         "threshold",
+		Feedback:       100,
+		Threshold:      0,
+		Kappa:          0.0,
+		RedisAddr:      "172.18.4.22:6379",
+		HTTPPort:       8001,
+		DataPort:       8002,
+		GRPCPort:       50051,
+		MetricsPort:    9090,
+		BackendPort:    80,
+		AdjacencyFile:  "./json/adjacentList.json",
+		LogFile:        "./log/output.csv",
+		SampleInterval: 100,
+		WatchInterval:  1000,
+		SuspectAfter:   3,
+		DownAfter:      10,
+		RecoverAfter:   2,
+		ReconnectMin:   100,
+		ReconnectMax:   5000,
+		Keepalive:      10000,
+		LegacyAPI:      true,
+		Capacity:       0,
+		EWMAAlpha:      0.3,
+		Normalize:      normalizeNone,
+		KappaMode:      kappaGlobal,
+		Beta:           1.0,
+		GradientLow:    0,
+		GossipEpoch:    0,
+		MeanMargin:     1.0,
+		JSQD:           2,
+		PIDKp:          0.5,
+		PIDKi:          0.5,
+		PIDKd:          0,
+		PIDMax:         0,
+		RTTCost:        rttCostLinear,
+		RTTScale:       50,
+		Estimator:      estimatorLast,
+		EstimatorAlpha: 0.5,
+		StaleAfter:     0,
+		CorrectTransit: false,
+		DeadBand:       0,
+		Dwell:          0,
+		Smoothing:      0,
+	}
+}
+
+// Register flags for every field of cfg, using the current values as defaults
+func bindFlags(fs *flag.FlagSet, cfg *Config) {
+	fs.StringVar(&cfg.NodeID, "node-id", cfg.NodeID, "own node ID in the adjacency list (detected from the local addresses if empty)")
+	fs.StringVar(&cfg.Leader, "leader", cfg.Leader, "node ID of the coordinator (default: cluster<cluster>)")
+	fs.IntVar(&cfg.Cluster, "cluster", cfg.Cluster, "target cluster of flash crowds")
+	fs.StringVar(&cfg.Policy, "policy", cfg.Policy, "forwarding policy ("+strings.Join(policyNames(), ", ")+")")
+	fs.IntVar(&cfg.Feedback, "feedback", cfg.Feedback, "feedback interval [ms]")
+	fs.IntVar(&cfg.Feedback, "t", cfg.Feedback, "shorthand for -feedback")
+	fs.IntVar(&cfg.Threshold, "threshold", cfg.Threshold, "threshold")
+	fs.IntVar(&cfg.Threshold, "q", cfg.Threshold, "shorthand for -threshold")
+	fs.Float64Var(&cfg.Kappa, "kappa", cfg.Kappa, "diffusion coefficient")
+	fs.Float64Var(&cfg.Kappa, "k", cfg.Kappa, "shorthand for -kappa")
+	fs.StringVar(&cfg.RedisAddr, "redis-addr", cfg.RedisAddr, "redis address (host:port)")
+	fs.IntVar(&cfg.HTTPPort, "http-port", cfg.HTTPPort, "port for requests")
+	fs.IntVar(&cfg.DataPort, "data-port", cfg.DataPort, "port for the CSV export")
+	fs.IntVar(&cfg.GRPCPort, "grpc-port", cfg.GRPCPort, "port for the feedback between adjacent LBs")
+	fs.IntVar(&cfg.MetricsPort, "metrics-port", cfg.MetricsPort, "port for the Prometheus exporter")
+	fs.IntVar(&cfg.BackendPort, "backend-port", cfg.BackendPort, "port of the web servers")
+	fs.StringVar(&cfg.AdjacencyFile, "adjacency-file", cfg.AdjacencyFile, "adjacency list (JSON)")
+	fs.StringVar(&cfg.LogFile, "log-file", cfg.LogFile, "CSV file of the measurement results")
+	fs.IntVar(&cfg.SampleInterval, "sample-interval", cfg.SampleInterval, "interval of the evaluation parameters [ms]")
+	fs.IntVar(&cfg.WatchInterval, "watch-interval", cfg.WatchInterval, "polling interval of the adjacency list [ms], 0 disables")
+	fs.IntVar(&cfg.SuspectAfter, "suspect-after", cfg.SuspectAfter, "unanswered feedback rounds before a neighbor is suspect")
+	fs.IntVar(&cfg.DownAfter, "down-after", cfg.DownAfter, "unanswered feedback rounds before a neighbor is down and reconnected")
+	fs.IntVar(&cfg.RecoverAfter, "recover-after", cfg.RecoverAfter, "answered feedback rounds before a suspect neighbor is healthy")
+	fs.IntVar(&cfg.ReconnectMin, "reconnect-min", cfg.ReconnectMin, "first reconnection backoff [ms]")
+	fs.IntVar(&cfg.ReconnectMax, "reconnect-max", cfg.ReconnectMax, "maximum reconnection backoff [ms]")
+	fs.IntVar(&cfg.Keepalive, "keepalive", cfg.Keepalive, "gRPC keepalive interval [ms], 0 disables")
+	fs.BoolVar(&cfg.LegacyAPI, "legacy-api", cfg.LegacyAPI, "serve and fall back to the feedback API of the builds before dc.v1")
+	fs.Float64Var(&cfg.Capacity, "capacity", cfg.Capacity, "capacity of the web servers advertised to the neighbors (0: number of web servers)")
+	fs.Float64Var(&cfg.EWMAAlpha, "ewma-alpha", cfg.EWMAAlpha, "smoothing factor of the arrival rate and the latency (0-1]")
+	fs.StringVar(&cfg.KappaMode, "kappa-mode", cfg.KappaMode, "diffusion coefficient of each edge (global: -kappa, metropolis: 1/(1+max(deg_i,deg_j)))")
+	fs.Float64Var(&cfg.Beta, "beta", cfg.Beta, "momentum of the second-order diffusion of the sos policy (0, 2), 1 is first-order")
+	fs.IntVar(&cfg.GradientLow, "gradient-low", cfg.GradientLow, "queue at or below which a cluster is lightly loaded (gradient policy)")
+	fs.IntVar(&cfg.GossipEpoch, "gossip-epoch", cfg.GossipEpoch, "restart interval of the gossip of the global mean load [ms], 0 disables (mean policy: max(2000, 4 feedback intervals))")
+	fs.Float64Var(&cfg.MeanMargin, "mean-margin", cfg.MeanMargin, "sessions above the global mean before forwarding (mean policy)")
+	fs.IntVar(&cfg.JSQD, "jsq-d", cfg.JSQD, "adjacent LBs sampled per request (jsq policy)")
+	fs.Float64Var(&cfg.PIDKp, "pid-kp", cfg.PIDKp, "proportional gain (pid policy)")
+	fs.Float64Var(&cfg.PIDKi, "pid-ki", cfg.PIDKi, "integral gain [1/s] (pid policy)")
+	fs.Float64Var(&cfg.PIDKd, "pid-kd", cfg.PIDKd, "derivative gain [s] (pid policy)")
+	fs.StringVar(&cfg.RTTCost, "rtt-cost", cfg.RTTCost, "discount of the flow by the round trip (linear: 1/(1+rtt/scale), exp: exp(-rtt/scale), cutoff: 0 beyond scale) (latency policy)")
+	fs.IntVar(&cfg.RTTScale, "rtt-scale", cfg.RTTScale, "round trip of the cost function [ms] (latency policy)")
+	fs.StringVar(&cfg.Estimator, "estimator", cfg.Estimator, "load of the neighbors between reports (last: last reported, trend: extrapolated and discounted when stale)")
+	fs.Float64Var(&cfg.EstimatorAlpha, "estimator-alpha", cfg.EstimatorAlpha, "gain of the trend estimator (0-1]")
+	fs.IntVar(&cfg.StaleAfter, "stale-after", cfg.StaleAfter, "age of a report beyond which the trend estimator discounts it [ms] (0: suspect_after feedback intervals)")
+	fs.Float64Var(&cfg.PIDMax, "pid-max", cfg.PIDMax, "largest flow to a neighbor [requests per feedback interval], 0 unlimited (pid policy)")
+	fs.BoolVar(&cfg.CorrectTransit, "correct-transit", cfg.CorrectTransit, "count the requests forwarded since the last report into the neighbor load and use the DC weight as a budget per report")
+	fs.IntVar(&cfg.DeadBand, "dead-band", cfg.DeadBand, "forwarding over a threshold stops only at the threshold minus this [sessions]")
+	fs.IntVar(&cfg.Dwell, "dwell", cfg.Dwell, "minimum time between two changes of the forwarding mode [ms]")
+	fs.IntVar(&cfg.Smoothing, "smoothing", cfg.Smoothing, "time constant of the signal compared with the threshold [ms], 0 disables")
+	fs.StringVar(&cfg.Normalize, "normalize", cfg.Normalize, "load compared by DC (none: queue, capacity: queue per capacity, rate: queue per service rate)")
+}
+
+// loadConfig resolves the configuration from args (without the program name).
+// printConfig reports whether -print-config was given.
+func loadConfig(args []string) (cfg Config, printConfig bool, err error) {
+	// The cluster number may still be given as the first positional argument
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		args = append([]string{"-cluster", args[0]}, args[1:]...)
+	}
+
+	// Parse the flags once to find the config file; the values are applied last
+	var path string
+	fs := flag.NewFlagSet("lb", flag.ExitOnError)
+	parsed := defaultConfig()
+	bindFlags(fs, &parsed)
+	fs.StringVar(&path, "config", "", "config file (.json, .yaml or .yml), also LB_CONFIG")
+	fs.BoolVar(&printConfig, "print-config", false, "print the resolved configuration and exit")
+	fs.Parse(args)
+
+	cfg = defaultConfig()
+	if path == "" {
+		path = os.Getenv("LB_CONFIG")
+	}
+	if path != "" {
+		if err := readConfigFile(path, &cfg); err != nil {
+			return cfg, printConfig, err
+		}
+	}
+
+	target := flag.NewFlagSet("lb", flag.ContinueOnError)
+	bindFlags(target, &cfg)
+
+	var errs []error
+	target.VisitAll(func(f *flag.Flag) {
+		// Shorthands have no environment variable
+		if len(f.Name) == 1 {
+			return
+		}
+		name := envName(f.Name)
+		if value, ok := os.LookupEnv(name); ok {
+			if err := target.Set(f.Name, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q: %v", name, value, err))
+			}
+		}
+	})
+	fs.Visit(func(f *flag.Flag) {
+		if target.Lookup(f.Name) != nil {
+			target.Set(f.Name, f.Value.String())
+		}
+	})
+	if len(errs) > 0 {
+		return cfg, printConfig, errors.Join(errs...)
+	}
+
+	// The flash crowd target coordinates the start unless a leader is given
+	if cfg.Leader == "" {
+		cfg.Leader = fmt.Sprintf("cluster%d", cfg.Cluster)
+	}
+
+	return cfg, printConfig, nil
+}
+
+// Environment variable overriding the flag name, e.g. redis-addr -> LB_REDIS_ADDR
+func envName(flagName string) string {
+	return "LB_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+func readConfigFile(path string, cfg *Config) error {
+	value, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(value))
+		dec.KnownFields(true)
+		err = dec.Decode(cfg)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(value))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(cfg)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid field at once
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(format string, a ...any) {
+		errs = append(errs, fmt.Errorf("  - "+format, a...))
+	}
+
+	if strings.TrimSpace(c.NodeID) != c.NodeID {
+		invalid("node_id %q must not have surrounding spaces", c.NodeID)
+	}
+	if c.Leader == "" {
+		invalid("leader must not be empty")
+	}
+	if c.Cluster < 0 {
+		invalid("cluster must be 0 or more (got %d)", c.Cluster)
+	}
+	if _, ok := policies[c.Policy]; !ok {
+		invalid("policy %q is unknown (available: %s)", c.Policy, strings.Join(policyNames(), ", "))
+	}
+	if c.Feedback <= 0 {
+		invalid("feedback must be a positive interval in ms (got %d)", c.Feedback)
+	}
+	if c.Threshold < 0 {
+		invalid("threshold must be 0 or more (got %d)", c.Threshold)
+	}
+	if c.Kappa < 0 {
+		invalid("kappa must be 0 or more (got %g)", c.Kappa)
+	}
+	if _, _, err := net.SplitHostPort(c.RedisAddr); err != nil {
+		invalid("redis_addr %q must be host:port: %v", c.RedisAddr, err)
+	}
+
+	ports := []struct {
+		name string
+		port int
+	}{
+		{"http_port", c.HTTPPort},
+		{"data_port", c.DataPort},
+		{"grpc_port", c.GRPCPort},
+		{"metrics_port", c.MetricsPort},
+		{"backend_port", c.BackendPort},
+	}
+	used := make(map[int]string)
+	for _, p := range ports {
+		if p.port < 1 || p.port > 65535 {
+			invalid("%s must be between 1 and 65535 (got %d)", p.name, p.port)
+			continue
+		}
+		// The web servers listen on other hosts, so only the LB's own ports must differ
+		if p.name == "backend_port" {
+			continue
+		}
+		if other, ok := used[p.port]; ok {
+			invalid("%s and %s use the same port %d", other, p.name, p.port)
+		}
+		used[p.port] = p.name
+	}
+
+	if c.AdjacencyFile == "" {
+		invalid("adjacency_file must not be empty")
+	}
+	if c.LogFile == "" {
+		invalid("log_file must not be empty")
+	}
+	if c.SampleInterval <= 0 {
+		invalid("sample_interval must be a positive interval in ms (got %d)", c.SampleInterval)
+	}
+
+	if c.WatchInterval < 0 {
+		invalid("watch_interval must be 0 (disabled) or a positive interval in ms (got %d)", c.WatchInterval)
+	}
+
+	if c.SuspectAfter < 1 {
+		invalid("suspect_after must be 1 or more (got %d)", c.SuspectAfter)
+	}
+	if c.DownAfter <= c.SuspectAfter {
+		invalid("down_after must be larger than suspect_after (got %d <= %d)", c.DownAfter, c.SuspectAfter)
+	}
+	if c.RecoverAfter < 1 {
+		invalid("recover_after must be 1 or more (got %d)", c.RecoverAfter)
+	}
+	if c.ReconnectMin <= 0 {
+		invalid("reconnect_min must be a positive interval in ms (got %d)", c.ReconnectMin)
+	}
+	if c.ReconnectMax < c.ReconnectMin {
+		invalid("reconnect_max must not be smaller than reconnect_min (got %d < %d)", c.ReconnectMax, c.ReconnectMin)
+	}
+	if c.Capacity < 0 {
+		invalid("capacity must be 0 (number of web servers) or more (got %g)", c.Capacity)
+	}
+	if c.EWMAAlpha <= 0 || c.EWMAAlpha > 1 {
+		invalid("ewma_alpha must be in (0, 1] (got %g)", c.EWMAAlpha)
+	}
+	switch c.KappaMode {
+	case kappaGlobal, kappaMetropolis:
+	default:
+		invalid("kappa_mode %q is unknown (available: %s, %s)", c.KappaMode, kappaGlobal, kappaMetropolis)
+	}
+	if c.Beta <= 0 || c.Beta >= 2 {
+		invalid("beta must be in (0, 2) (got %g)", c.Beta)
+	}
+	if c.GradientLow < 0 || (c.Policy == "gradient" && c.GradientLow > c.Threshold) {
+		invalid("gradient_low must be between 0 and threshold (got %d, threshold %d)", c.GradientLow, c.Threshold)
+	}
+	if c.GossipEpoch < 0 || (c.GossipEpoch > 0 && c.GossipEpoch < 4*c.Feedback) {
+		invalid("gossip_epoch must be 0 (disabled) or at least 4 feedback intervals (got %d)", c.GossipEpoch)
+	}
+	if c.MeanMargin < 0 {
+		invalid("mean_margin must be 0 or more (got %g)", c.MeanMargin)
+	}
+	if c.JSQD < 1 {
+		invalid("jsq_d must be 1 or more (got %d)", c.JSQD)
+	}
+	if c.PIDKp < 0 || c.PIDKi < 0 || c.PIDKd < 0 {
+		invalid("pid_kp, pid_ki and pid_kd must be 0 or more (got %g, %g, %g)", c.PIDKp, c.PIDKi, c.PIDKd)
+	}
+	if c.PIDMax < 0 {
+		invalid("pid_max must be 0 (unlimited) or more (got %g)", c.PIDMax)
+	}
+	switch c.RTTCost {
+	case rttCostLinear, rttCostExp, rttCostCutoff:
+	default:
+		invalid("rtt_cost %q is unknown (available: %s, %s, %s)", c.RTTCost, rttCostLinear, rttCostExp, rttCostCutoff)
+	}
+	if c.RTTScale <= 0 {
+		invalid("rtt_scale must be positive (got %d)", c.RTTScale)
+	}
+	switch c.Estimator {
+	case estimatorLast, estimatorTrend:
+	default:
+		invalid("estimator %q is unknown (available: %s, %s)", c.Estimator, estimatorLast, estimatorTrend)
+	}
+	if c.EstimatorAlpha <= 0 || c.EstimatorAlpha > 1 {
+		invalid("estimator_alpha must be in (0, 1] (got %g)", c.EstimatorAlpha)
+	}
+	if c.StaleAfter < 0 {
+		invalid("stale_after must be 0 or more (got %d)", c.StaleAfter)
+	}
+	if c.DeadBand < 0 || c.Dwell < 0 || c.Smoothing < 0 {
+		invalid("dead_band, dwell and smoothing must be 0 or more (got %d, %d, %d)", c.DeadBand, c.Dwell, c.Smoothing)
+	}
+	switch c.Normalize {
+	case normalizeNone, normalizeCapacity, normalizeRate:
+	default:
+		invalid("normalize %q is unknown (available: %s, %s, %s)", c.Normalize, normalizeNone, normalizeCapacity, normalizeRate)
+	}
+	if c.Keepalive != 0 && c.Keepalive < int(minKeepalive/time.Millisecond) {
+		invalid("keepalive must be 0 (disabled) or at least %d ms (got %d)", minKeepalive/time.Millisecond, c.Keepalive)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (c Config) String() string {
+	out, _ := json.MarshalIndent(c, "", "  ")
+	return string(out)
+}
+
+// Address of a port on the local host (":8001")
+func listenAddr(port int) string {
+	return fmt.Sprintf(":%d", port)
+}
+
+// Listen on all interfaces with the port of addr (host:port)
+func listenOn(addr string) string {
+	_, port, _ := net.SplitHostPort(addr)
+	return ":" + port
+}
+
+// Default ports of the addresses in the adjacency list
+func topologyPorts() topology.Ports {
+	return topology.Ports{
+		HTTP:    config.HTTPPort,
+		GRPC:    config.GRPCPort,
+		Backend: config.BackendPort,
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string // YAML config file, none if empty
+		env       map[string]string
+		args      []string
+		feedback  int
+		threshold int
+		policy    string
+		leader    string
+	}{
+		{
+			name:     "defaults",
+			feedback: 100, threshold: 0, policy: "threshold", leader: "cluster0",
+		},
+		{
+			name:     "file over defaults",
+			file:     "feedback: 200\nthreshold: 3\n",
+			feedback: 200, threshold: 3, policy: "threshold", leader: "cluster0",
+		},
+		{
+			name:     "env over file",
+			file:     "feedback: 200\nthreshold: 3\n",
+			env:      map[string]string{"LB_FEEDBACK": "300"},
+			feedback: 300, threshold: 3, policy: "threshold", leader: "cluster0",
+		},
+		{
+			name:     "flags over env",
+			file:     "feedback: 200\nthreshold: 3\npolicy: diff\n",
+			env:      map[string]string{"LB_FEEDBACK": "300", "LB_THRESHOLD": "4"},
+			args:     []string{"-t", "400", "-policy", "flow"},
+			feedback: 400, threshold: 4, policy: "flow", leader: "cluster0",
+		},
+		{
+			name:     "positional cluster names the leader",
+			args:     []string{"2", "-q", "5"},
+			feedback: 100, threshold: 5, policy: "threshold", leader: "cluster2",
+		},
+		{
+			name:     "explicit leader",
+			env:      map[string]string{"LB_LEADER": "cluster1"},
+			args:     []string{"-cluster", "2"},
+			feedback: 100, threshold: 0, policy: "threshold", leader: "cluster1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LB_CONFIG", "")
+			if tt.file != "" {
+				path := filepath.Join(t.TempDir(), "lb.yaml")
+				if err := os.WriteFile(path, []byte(tt.file), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				t.Setenv("LB_CONFIG", path)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, _, err := loadConfig(tt.args)
+			if err != nil {
+				t.Fatalf("loadConfig: %v", err)
+			}
+			if cfg.Feedback != tt.feedback || cfg.Threshold != tt.threshold || cfg.Policy != tt.policy || cfg.Leader != tt.leader {
+				t.Errorf("got feedback %d, threshold %d, policy %q, leader %q; want %d, %d, %q, %q",
+					cfg.Feedback, cfg.Threshold, cfg.Policy, cfg.Leader, tt.feedback, tt.threshold, tt.policy, tt.leader)
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string // file name and content
+		body string
+		env  map[string]string
+		want string
+	}{
+		{name: "unknown yaml field", file: "lb.yaml", body: "feedbak: 200\n", want: "failed to parse config file"},
+		{name: "unknown json field", file: "lb.json", body: `{"feedbak": 200}`, want: "failed to parse config file"},
+		{name: "missing file", want: "failed to read config file"},
+		{name: "bad env value", env: map[string]string{"LB_FEEDBACK": "fast"}, want: `LB_FEEDBACK="fast"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LB_CONFIG", "")
+			dir := t.TempDir()
+			if tt.file != "" {
+				if err := os.WriteFile(filepath.Join(dir, tt.file), []byte(tt.body), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				t.Setenv("LB_CONFIG", filepath.Join(dir, tt.file))
+			} else if tt.env == nil {
+				t.Setenv("LB_CONFIG", filepath.Join(dir, "missing.yaml"))
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, _, err := loadConfig(nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string // substrings of the error, none for a valid config
+	}{
+		{name: "defaults", modify: func(c *Config) {}},
+		{name: "unknown policy", modify: func(c *Config) { c.Policy = "fastest" }, want: []string{`policy "fastest" is unknown`}},
+		{name: "feedback", modify: func(c *Config) { c.Feedback = 0 }, want: []string{"feedback must be a positive interval"}},
+		{name: "empty leader", modify: func(c *Config) { c.Leader = "" }, want: []string{"leader must not be empty"}},
+		{name: "redis address", modify: func(c *Config) { c.RedisAddr = "redis" }, want: []string{`redis_addr "redis" must be host:port`}},
+		{name: "port range", modify: func(c *Config) { c.GRPCPort = 70000 }, want: []string{"grpc_port must be between 1 and 65535"}},
+		{name: "port clash", modify: func(c *Config) { c.DataPort = c.HTTPPort }, want: []string{"http_port and data_port use the same port"}},
+		{name: "backend port may equal an LB port", modify: func(c *Config) { c.BackendPort = c.HTTPPort }},
+		{name: "health timeouts", modify: func(c *Config) { c.DownAfter = c.SuspectAfter }, want: []string{"down_after must be larger than suspect_after"}},
+		{name: "gossip epoch", modify: func(c *Config) { c.GossipEpoch = 2 * c.Feedback }, want: []string{"gossip_epoch must be 0 (disabled) or at least 4 feedback intervals"}},
+		{name: "long feedback without gossip", modify: func(c *Config) { c.Feedback = 1000 }},
+		{name: "estimator", modify: func(c *Config) { c.Estimator = "kalman"; c.EstimatorAlpha = 0 }, want: []string{
+			`estimator "kalman" is unknown`,
+			"estimator_alpha must be in (0, 1]",
+		}},
+		{name: "dead band", modify: func(c *Config) { c.DeadBand = -1 }, want: []string{"dead_band, dwell and smoothing must be 0 or more"}},
+		{name: "every error at once", modify: func(c *Config) { c.Kappa = -1; c.JSQD = 0; c.RTTScale = 0 }, want: []string{
+			"kappa must be 0 or more",
+			"jsq_d must be 1 or more",
+			"rtt_scale must be positive",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaultConfig()
+			c.Leader = "cluster0"
+			tt.modify(&c)
+
+			err := c.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("no error, want %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// Parameters of the edges to the adjacent LBs (mutex held). Attributes set
+// on an edge in the adjacency list (kappa, threshold, cost, max_rate) take
+// precedence over the LB configuration.
+
+// Diffusion coefficient of the edge to the adjacent LB clusterLBs[num].
+// With kappa_mode metropolis it is the Metropolis-Hastings weight
+// 1/(1+max(deg_i,deg_j)), so that hubs with many neighbors do not forward
+// more than their load allows; until the neighbor reports its degree (or
+// with the legacy API), the global kappa is used.
+func edgeKappa(num int) float64 {
+	kappa := config.Kappa
+	if k := clusterLBs[num].Edge.Kappa; k != nil {
+		kappa = *k
+	} else if config.KappaMode == kappaMetropolis && clusterLBs[num].Degree > 0 {
+		kappa = 1 / float64(1+max(len(clusterLBs), clusterLBs[num].Degree))
+	}
+
+	if clusterLBs[num].Kappa != kappa {
+		clusterLBs[num].Kappa = kappa
+		edgeKappas.WithLabelValues(ownNodeID, clusterLBs[num].ID).Set(kappa)
+	}
+	return kappa
+}
+
+// Export the diffusion coefficient of a new or changed edge to
+// clusterLBs[num]. edgeKappa only exports changes of the value, which would
+// leave an edge whose kappa stays 0 without a series.
+func exportEdgeKappa(num int) {
+	edgeKappas.WithLabelValues(ownNodeID, clusterLBs[num].ID).Set(edgeKappa(num))
+}
+
+// Threshold of forwarding over the edge to clusterLBs[num]
+func edgeThreshold(num int) int {
+	if t := clusterLBs[num].Edge.Threshold; t != nil {
+		return *t
+	}
+	return config.Threshold
+}
+
+// Whether the own queue exceeds the threshold of the edge to clusterLBs[num]
+// (with the hysteresis of the edge)
+func overThreshold(num int) bool {
+	return clusterLBs[num].Mode.update(float64(queue), edgeThreshold(num), time.Now(), ownNodeID, clusterLBs[num].ID)
+}
+
+// Forwarding mode without adjacent LBs
+var ownMode modeSwitch
+
+// Whether the own queue exceeds the threshold of some edge. Without adjacent
+// LBs it is the global threshold, as before edges had their own.
+func anyOverThreshold() bool {
+	if len(clusterLBs) == 0 {
+		return ownMode.update(float64(queue), config.Threshold, time.Now(), ownNodeID, "")
+	}
+	for i := range clusterLBs {
+		if overThreshold(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hysteresis of threshold forwarding over an edge. Forwarding switches on
+// when the signal (the own queue, or the load difference with the diff
+// policy) exceeds the threshold and off only when it falls to the threshold
+// minus dead_band (at least 0, which an idle queue reaches), and a mode is
+// kept for at least dwell ms, so that a queue near the threshold does not
+// toggle forwarding with every request. With smoothing, the signal is first
+// averaged with that time constant [ms].
+// With the defaults (all 0), it is the plain comparison signal > threshold.
+type modeSwitch struct {
+	On      bool
+	Since   time.Time // Last change of the mode
+	Changes int       // Number of mode changes
+	Signal  float64   // Smoothed signal
+	At      time.Time // Last update of the signal
+}
+
+func (s *modeSwitch) update(signal float64, threshold int, now time.Time, cluster, neighbor string) bool {
+	if config.Smoothing > 0 && !s.At.IsZero() {
+		a := 1 - math.Exp(-float64(now.Sub(s.At))/float64(time.Duration(config.Smoothing)*time.Millisecond))
+		signal = s.Signal + a*(signal-s.Signal)
+	}
+	s.Signal, s.At = signal, now
+
+	on := s.On
+	if !s.On && signal > float64(threshold) {
+		on = true
+	} else if s.On && signal <= float64(max(threshold-config.DeadBand, 0)) {
+		on = false
+	}
+	if on != s.On && now.Sub(s.Since) >= time.Duration(config.Dwell)*time.Millisecond {
+		s.On, s.Since = on, now
+		s.Changes++
+		modeChanges.WithLabelValues(cluster, neighbor).Inc()
+	}
+	return s.On
+}
+
+// Take one request from the max_rate budget of the edge to clusterLBs[num].
+// The budget refills at max_rate requests per second and holds up to one
+// feedback interval of requests (at least one); edges without max_rate are
+// unlimited. Requests over the budget are counted in Limited.
+func allowRate(num int, now time.Time) bool {
+	lb := &clusterLBs[num]
+	rate := lb.Edge.MaxRate
+	if rate <= 0 {
+		return true
+	}
+
+	burst := max(rate*float64(config.Feedback)/1000, 1)
+	if lb.RateRefilled.IsZero() {
+		lb.RateTokens = burst
+	} else {
+		lb.RateTokens = min(lb.RateTokens+rate*now.Sub(lb.RateRefilled).Seconds(), burst)
+	}
+	lb.RateRefilled = now
+
+	if lb.RateTokens < 1 {
+		lb.Limited++
+		return false
+	}
+	lb.RateTokens--
+	return true
+}
+
+// Select the adjacent LB to forward to with the policy, leaving out the edges
+// over their max_rate budget: a rejected edge is added to the skip set of the
+// request and the policy selects again. The token the policy spent on a
+// rejected edge is refunded. After one attempt per adjacent LB, or when the
+// policy gives up, the request is processed locally (-1). Must be called
+// with mutex held
+func selectWithinRate(now time.Time) int {
+	var skip []bool
+	for range clusterLBs {
+		next := policy.Select(skip)
+		if next < 0 || allowRate(next, now) {
+			return next
+		}
+		if t, ok := policy.(tokenSpender); ok {
+			t.refund(next)
+		}
+		if skip == nil {
+			skip = make([]bool, len(clusterLBs))
+		}
+		skip[next] = true
+	}
+	return -1
+}
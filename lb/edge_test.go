@@ -0,0 +1,288 @@
+package main
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestModeSwitch(t *testing.T) {
+	type step struct {
+		at     int // [ms] since the first update
+		signal float64
+		on     bool
+	}
+	tests := []struct {
+		name      string
+		threshold int
+		deadBand  int
+		dwell     int
+		smoothing int
+		steps     []step
+		changes   int
+	}{
+		{
+			name: "plain comparison", threshold: 5,
+			steps: []step{
+				{0, 5, false}, {1, 6, true}, {2, 5, false}, {3, 6, true}, {4, 0, false},
+			},
+			changes: 4,
+		},
+		{
+			name: "dead band", threshold: 5, deadBand: 2,
+			steps: []step{
+				{0, 6, true}, {1, 5, true}, {2, 4, true}, {3, 3, false}, {4, 5, false}, {5, 6, true},
+			},
+			changes: 3,
+		},
+		{
+			name: "dead band beyond the threshold stops at 0", threshold: 2, deadBand: 5,
+			steps: []step{
+				{0, 3, true}, {1, 1, true}, {2, 0, false},
+			},
+			changes: 2,
+		},
+		{
+			name: "dwell", threshold: 5, dwell: 100,
+			steps: []step{
+				{0, 6, true}, {50, 0, true}, {99, 0, true}, {100, 0, false},
+				{150, 6, false}, {199, 6, false}, {200, 6, true},
+			},
+			changes: 3,
+		},
+		{
+			name: "dwell with a dead band", threshold: 5, deadBand: 2, dwell: 100,
+			steps: []step{
+				{0, 6, true}, {120, 4, true}, {130, 3, false}, {200, 6, false}, {230, 6, true},
+			},
+			changes: 3,
+		},
+		{
+			name: "smoothing ignores a spike", threshold: 5, smoothing: 100,
+			steps: []step{
+				{0, 0, false}, {10, 10, false}, {20, 0, false}, {520, 10, true},
+			},
+			changes: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaultConfig()
+			c.DeadBand, c.Dwell, c.Smoothing = tt.deadBand, tt.dwell, tt.smoothing
+			withNeighbors(t, c, nil, 0)
+
+			var s modeSwitch
+			start := time.Unix(1000, 0)
+			for _, st := range tt.steps {
+				now := start.Add(time.Duration(st.at) * time.Millisecond)
+				if got := s.update(st.signal, tt.threshold, now, "test", "neighbor"); got != st.on {
+					t.Errorf("at %d ms with signal %g: on = %v, want %v", st.at, st.signal, got, st.on)
+				}
+			}
+			if s.Changes != tt.changes {
+				t.Errorf("changes = %d, want %d", s.Changes, tt.changes)
+			}
+		})
+	}
+}
+
+// Two idle adjacent LBs that every policy may forward to; the first one is
+// over its max_rate budget at now
+func rateLimitedNeighbors(now time.Time) []LoadBalancer {
+	lbs := make([]LoadBalancer, 2)
+	for i := range lbs {
+		lbs[i] = LoadBalancer{
+			IsHealthy:  true,
+			Weight:     10,
+			Tokens:     5,
+			Servers:    2,
+			ServerRate: 10,
+		}
+	}
+	lbs[0].ID, lbs[1].ID = "cluster1", "cluster2"
+	lbs[0].Edge.MaxRate, lbs[0].RateRefilled = 1, now
+	return lbs
+}
+
+// Replace the policy and the own web servers for one test
+func withPolicy(t *testing.T, name string) {
+	t.Helper()
+	oldPolicy, oldServers, oldRate := policy, webServers, serverRate
+	t.Cleanup(func() { policy, webServers, serverRate = oldPolicy, oldServers, oldRate })
+	p, err := newPolicy(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, webServers, serverRate = p, make([]webServer, 2), 10
+}
+
+func TestSelectWithinRateEveryPolicy(t *testing.T) {
+	// Policies that do not forward without state negotiated with the neighbors
+	local := map[string]bool{"local": true, "dx": true}
+
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			now := time.Unix(1000, 0)
+			withNeighbors(t, defaultConfig(), rateLimitedNeighbors(now), 50)
+			withPolicy(t, name)
+
+			done := make(chan int, 1)
+			go func() { done <- selectWithinRate(now) }()
+			var got int
+			select {
+			case got = <-done:
+			case <-time.After(2 * time.Second):
+				t.Fatal("selectWithinRate did not return")
+			}
+
+			want := 1
+			if local[name] {
+				want = -1
+			}
+			if got != want {
+				t.Errorf("selectWithinRate = %d, want %d", got, want)
+			}
+			for i, lb := range clusterLBs {
+				if !lb.IsHealthy {
+					t.Errorf("neighbor %d left unhealthy", i)
+				}
+			}
+		})
+	}
+}
+
+// Policy that keeps selecting the first adjacent LB, ignoring skip
+type stuckPolicy struct{}
+
+func (p *stuckPolicy) Forward() bool                     { return true }
+func (p *stuckPolicy) Select(skip []bool) int            { return 0 }
+func (p *stuckPolicy) Calculate(next_queue int, num int) {}
+
+func TestSelectWithinRateIgnoredSkip(t *testing.T) {
+	now := time.Unix(1000, 0)
+	withNeighbors(t, defaultConfig(), rateLimitedNeighbors(now), 50)
+	withPolicy(t, "local")
+	policy = &stuckPolicy{}
+
+	if got := selectWithinRate(now); got != -1 {
+		t.Errorf("selectWithinRate = %d, want -1", got)
+	}
+	if got, want := clusterLBs[0].Limited, len(clusterLBs); got != want {
+		t.Errorf("Limited = %d, want one per attempt (%d)", got, want)
+	}
+}
+
+func TestSelectWithinRateRefund(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		limited []bool // edges over their max_rate
+		weights []int
+		paired  string // partner of dx
+		want    int
+		tokens  []float64 // after the selection
+		counted []int     // Limited after the selection
+	}{
+		{
+			name: "within the budget", policy: "flow",
+			limited: []bool{false, false}, weights: []int{10, 10},
+			want: 0, tokens: []float64{4, 3}, counted: []int{0, 0},
+		},
+		{
+			name: "rejected edge skipped and refunded", policy: "flow",
+			limited: []bool{true, false}, weights: []int{10, 10},
+			want: 1, tokens: []float64{5, 2}, counted: []int{1, 0},
+		},
+		{
+			name: "every edge rejected", policy: "flow",
+			limited: []bool{true, true}, weights: []int{10, 10},
+			want: -1, tokens: []float64{5, 3}, counted: []int{1, 1},
+		},
+		{
+			name: "paired edge rejected", policy: "dx", paired: "cluster1",
+			limited: []bool{true, false}, weights: []int{10, 10},
+			want: -1, tokens: []float64{5, 3}, counted: []int{1, 0},
+		},
+		{
+			name: "no token to refund", policy: "threshold",
+			limited: []bool{true, false}, weights: []int{10, 0},
+			want: -1, tokens: []float64{5, 3}, counted: []int{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1000, 0)
+			lbs := rateLimitedNeighbors(now)
+			lbs[1].Tokens = 3
+			for i := range lbs {
+				lbs[i].Weight = tt.weights[i]
+				if tt.limited[i] {
+					lbs[i].Edge.MaxRate, lbs[i].RateRefilled = 1, now
+				} else {
+					lbs[i].Edge.MaxRate = 0
+				}
+			}
+			withNeighbors(t, defaultConfig(), lbs, 50)
+			withPolicy(t, tt.policy)
+			if dx, ok := policy.(*dimensionExchange); ok {
+				dx.partner, dx.until = tt.paired, time.Now().Add(time.Hour)
+			}
+
+			if got := selectWithinRate(now); got != tt.want {
+				t.Errorf("selectWithinRate = %d, want %d", got, tt.want)
+			}
+			for i, lb := range clusterLBs {
+				if lb.Tokens != tt.tokens[i] {
+					t.Errorf("neighbor %d: tokens = %g, want %g", i, lb.Tokens, tt.tokens[i])
+				}
+				if lb.Limited != tt.counted[i] {
+					t.Errorf("neighbor %d: Limited = %d, want %d", i, lb.Limited, tt.counted[i])
+				}
+				if !lb.IsHealthy {
+					t.Errorf("neighbor %d left unhealthy", i)
+				}
+			}
+		})
+	}
+}
+
+func TestExportEdgeKappa(t *testing.T) {
+	edge := 0.3
+	tests := []struct {
+		name  string
+		kappa float64  // global
+		edge  *float64 // of the edge
+		want  float64
+	}{
+		{name: "kappa 0", kappa: 0, want: 0},
+		{name: "global kappa", kappa: 0.5, want: 0.5},
+		{name: "kappa of the edge", kappa: 0.5, edge: &edge, want: 0.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaultConfig()
+			c.Kappa = tt.kappa
+			lbs := []LoadBalancer{{ID: "cluster1"}}
+			lbs[0].Edge.Kappa = tt.edge
+			withNeighbors(t, c, lbs, 0)
+
+			exportEdgeKappa(0)
+			if got := clusterLBs[0].Kappa; got != tt.want {
+				t.Errorf("Kappa = %g, want %g", got, tt.want)
+			}
+			// Deleting reports whether the series was exported
+			if !edgeKappas.DeleteLabelValues(ownNodeID, "cluster1") {
+				t.Error("edge_kappa is not exported")
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// Estimate of the current load of the adjacent LBs between their reports
+// (mutex held). With estimator last, the policies use the queue of the last
+// report as it is. With estimator trend, an alpha-beta filter (the steady
+// state Kalman filter of a load changing at a constant rate) tracks the
+// level and the slope of the reported queue, and the load is extrapolated
+// along the slope up to stale_after after the last report. Older reports are
+// discounted: the estimate decays toward the own queue, so that a neighbor
+// whose reports stopped neither attracts nor repels load.
+type loadEstimate struct {
+	Level float64   // Filtered queue at the last report
+	Trend float64   // Slope of the queue [sessions/s]
+	At    time.Time // Last report
+}
+
+// Values of estimator
+const (
+	estimatorLast  = "last"
+	estimatorTrend = "trend"
+)
+
+// Take the report just applied to clusterLBs[num]. The slope is corrected
+// over at least one round (roundInterval).
+func updateEstimate(num int, now time.Time) {
+	lb := &clusterLBs[num]
+	e := &lb.Estimate
+	x := float64(lb.Data)
+	dt := now.Sub(e.At).Seconds()
+	if e.At.IsZero() || now.Sub(e.At) > staleAfter() || dt <= 0 {
+		*e = loadEstimate{Level: x, At: now}
+		return
+	}
+
+	alpha := config.EstimatorAlpha
+	beta := alpha * alpha / (2 - alpha) // Benedict-Bordner relation of the alpha-beta filter
+	predicted := e.Level + e.Trend*dt
+	residual := x - predicted
+	e.Level = predicted + alpha*residual
+	e.Trend += beta * residual / max(dt, roundInterval().Seconds())
+	e.At = now
+}
+
+// Estimated queue of clusterLBs[num] at now
+func estimateLoad(num int, now time.Time) float64 {
+	lb := &clusterLBs[num]
+	if config.Estimator == estimatorLast || lb.Estimate.At.IsZero() {
+		return float64(lb.Data)
+	}
+
+	e := lb.Estimate
+	age, stale := now.Sub(e.At), staleAfter()
+	load := e.Level + e.Trend*min(age, stale).Seconds()
+	if age > stale {
+		w := math.Exp(-float64(age-stale) / float64(stale))
+		load = w*load + (1-w)*float64(queue)
+	}
+	return max(load, 0)
+}
+
+// Load of clusterLBs[num] used by the policies, in sessions. With
+// correct_transit, the requests forwarded to the neighbor since its
+// last report are added, so that an overloaded LB does not keep sending to
+// the same neighbor until the next report shows them (herding).
+func neighborLoad(num int) int {
+	load := int(math.Round(estimateLoad(num, time.Now())))
+	if config.CorrectTransit {
+		load += clusterLBs[num].InTransit
+	}
+	return load
+}
+
+// Whether clusterLBs[num] has weight left for requests until its next
+// report: with correct_transit, the weight of the DC policies is a
+// budget of requests per report, used up by the requests forwarded since.
+// InTransit is reset by every report received from the neighbor, on either
+// stream (its own reports and its answers to ours), so the budget covers the
+// time between two reports, not one feedback interval. The central policy
+// resets it when the controller sets the weights instead.
+func withinBudget(num int) bool {
+	return !config.CorrectTransit || clusterLBs[num].InTransit < clusterLBs[num].Weight
+}
+
+// Age of a report beyond which it is discounted; stale_after 0 is the
+// suspect timeout of the feedback
+func staleAfter() time.Duration {
+	if config.StaleAfter > 0 {
+		return time.Duration(config.StaleAfter) * time.Millisecond
+	}
+	return time.Duration(config.Feedback*config.SuspectAfter) * time.Millisecond
+}
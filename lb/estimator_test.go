@@ -0,0 +1,142 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// Replace the globals used by the estimator for one test
+func withNeighbors(t *testing.T, c Config, lbs []LoadBalancer, q int) {
+	t.Helper()
+	oldConfig, oldLBs, oldQueue := config, clusterLBs, queue
+	t.Cleanup(func() { config, clusterLBs, queue = oldConfig, oldLBs, oldQueue })
+	config, clusterLBs, queue = c, lbs, q
+}
+
+func TestEstimateLoad(t *testing.T) {
+	const (
+		interval = 100 * time.Millisecond // feedback
+		stale    = 500 * time.Millisecond // stale_after
+		own      = 30                     // own queue
+	)
+	decay := math.Exp(-1) // Weight of the extrapolation one stale_after past stale
+
+	tests := []struct {
+		name      string
+		estimator string
+		start     float64       // first reported queue
+		slope     float64       // change of the reported queue [sessions/s]
+		reports   int           // reports, one per feedback interval
+		gap       float64       // with gap > 0, one more report of this queue after 2 stale_after
+		age       time.Duration // since the last report
+		want      float64
+		tolerance float64
+	}{
+		{
+			name: "last reported", estimator: estimatorLast,
+			start: 10, slope: 20, reports: 40, age: 200 * time.Millisecond,
+			want: 10 + 20*3.9,
+		},
+		{
+			name: "single report", estimator: estimatorTrend,
+			start: 10, reports: 1, age: 200 * time.Millisecond,
+			want: 10,
+		},
+		{
+			name: "rising queue", estimator: estimatorTrend,
+			start: 10, slope: 20, reports: 40, age: 200 * time.Millisecond,
+			want: 10 + 20*(3.9+0.2), tolerance: 0.1,
+		},
+		{
+			name: "falling queue", estimator: estimatorTrend,
+			start: 100, slope: -20, reports: 40, age: 200 * time.Millisecond,
+			want: 100 - 20*(3.9+0.2), tolerance: 0.1,
+		},
+		{
+			name: "extrapolated up to stale_after", estimator: estimatorTrend,
+			start: 10, slope: 20, reports: 40, age: stale,
+			want: 10 + 20*(3.9+0.5), tolerance: 0.1,
+		},
+		{
+			name: "stale report decays toward the own queue", estimator: estimatorTrend,
+			start: 10, slope: 20, reports: 40, age: 2 * stale,
+			want: decay*(10+20*(3.9+0.5)) + (1-decay)*own, tolerance: 0.1,
+		},
+		{
+			name: "long stale report is the own queue", estimator: estimatorTrend,
+			start: 10, slope: 20, reports: 40, age: 20 * stale,
+			want: own, tolerance: 1e-3,
+		},
+		{
+			name: "never negative", estimator: estimatorTrend,
+			start: 20, slope: -20, reports: 10, age: stale,
+			want: 0,
+		},
+		{
+			name: "report after a gap restarts the trend", estimator: estimatorTrend,
+			start: 10, slope: 20, reports: 40, gap: 50, age: 200 * time.Millisecond,
+			want: 50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaultConfig()
+			c.Feedback = int(interval / time.Millisecond)
+			c.StaleAfter = int(stale / time.Millisecond)
+			c.Estimator = tt.estimator
+			c.EstimatorAlpha = 0.5
+			withNeighbors(t, c, make([]LoadBalancer, 1), own)
+
+			at := time.Unix(1000, 0)
+			for i := 0; i < tt.reports; i++ {
+				if i > 0 {
+					at = at.Add(interval)
+				}
+				clusterLBs[0].Data = int(math.Round(tt.start + tt.slope*float64(i)*interval.Seconds()))
+				updateEstimate(0, at)
+			}
+			if tt.gap > 0 {
+				at = at.Add(2 * stale)
+				clusterLBs[0].Data = int(tt.gap)
+				updateEstimate(0, at)
+			}
+
+			if got := estimateLoad(0, at.Add(tt.age)); math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("estimateLoad = %.4f, want %.4f (±%g)", got, tt.want, tt.tolerance)
+			}
+		})
+	}
+}
+
+func TestNeighborLoad(t *testing.T) {
+	tests := []struct {
+		name      string
+		correct   bool
+		inTransit int
+		weight    int
+		load      int
+		within    bool
+	}{
+		{name: "without correct_transit", inTransit: 4, weight: 3, load: 7, within: true},
+		{name: "in transit added", correct: true, inTransit: 2, weight: 3, load: 9, within: true},
+		{name: "budget used up", correct: true, inTransit: 3, weight: 3, load: 10, within: false},
+		{name: "no weight", correct: true, weight: 0, load: 7, within: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaultConfig()
+			c.CorrectTransit = tt.correct
+			withNeighbors(t, c, []LoadBalancer{{Data: 7, InTransit: tt.inTransit, Weight: tt.weight}}, 0)
+
+			if got := neighborLoad(0); got != tt.load {
+				t.Errorf("neighborLoad = %d, want %d", got, tt.load)
+			}
+			if got := withinBudget(0); got != tt.within {
+				t.Errorf("withinBudget = %v, want %v", got, tt.within)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"custome_weightedRR/topology"
+)
+
+type Response struct {
+	TotalQueue          []int
+	CurrentQueue        []int
+	FirstReceivedQueue  []int
+	SecondReceivedQueue []int
+	CurrentResponse     []int
+	CurrentTransport    []int
+	InFlight            []int
+	ArrivalRate         []float64
+	Latency             []float64
+	ServiceRate         []float64
+	ServerRate          []float64
+	Wait                []float64
+	GossipMean          []float64
+	GossipCount         []float64
+	GossipCountError    []float64
+	GossipSpread        []float64
+	Neighbors           []map[string]neighborSample
+	Session             []int
+}
+
+type splitWebServer struct {
+	Session []int
+}
+
+func dataReceiver(w http.ResponseWriter, r *http.Request) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	// Obtain data for each parameter after the load test ends
+	fmt.Printf("total_request: %d\n", totalQueue)
+	fmt.Printf("queue_transition: %d\n", currentQueue)
+
+	for i := 0; i < len(clusterLBs); i++ {
+		fmt.Printf("amount of transport(%s): %d\n", clusterLBs[i].Address, clusterLBs[i].Transport)
+	}
+
+	// Every adjacent LB seen during the test gets its columns; ticks when it
+	// was not adjacent are left empty
+	ids := append([]string(nil), neighborIDs...)
+	topology.SortIDs(ids)
+
+	backends := make([]splitWebServer, len(webServers))
+	for i := 0; i < len(session); i++ {
+		backendsIndex := i % len(webServers)
+		backends[backendsIndex].Session = append(backends[backendsIndex].Session, session[i])
+	}
+
+	response := Response{
+		TotalQueue:          totalData,
+		CurrentQueue:        currentQueue,
+		FirstReceivedQueue:  firstReceivedQueue,
+		SecondReceivedQueue: secondReceivedQueue,
+		CurrentResponse:     currentResponse,
+		CurrentTransport:    totalTransport,
+		InFlight:            inFlightQueue,
+		ArrivalRate:         arrivalRates,
+		Latency:             latencies,
+		ServiceRate:         serviceRates,
+		ServerRate:          serverRates,
+		Wait:                waits,
+		GossipMean:          gossipMeans,
+		GossipCount:         gossipCounts,
+		GossipCountError:    gossipCountErrors,
+		GossipSpread:        gossipSpreads,
+		Neighbors:           neighborHistory,
+		Session:             session,
+	}
+
+	file, err := os.Create(config.LogFile)
+	if err != nil {
+		fmt.Println("failure creating csv file:", err)
+		return
+	}
+	defer file.Close()
+
+	var csvData strings.Builder
+	header := []string{"TotalQueue"}
+	header = append(header, "Queue")
+	header = append(header, "FirstReceivedQueue")
+	header = append(header, "SecondReceivedQueue")
+	header = append(header, "CurrentResponse")
+	header = append(header, "CurrentTransport")
+	header = append(header, "InFlight")
+	header = append(header, "ArrivalRate")
+	header = append(header, "Latency")
+	header = append(header, "ServiceRate")
+	header = append(header, "ServerRate")
+	header = append(header, "Wait")
+	header = append(header, "GossipMean")
+	header = append(header, "GossipCount")
+	header = append(header, "GossipCountError")
+	header = append(header, "GossipSpread")
+	for _, column := range neighborColumns {
+		for _, id := range ids {
+			header = append(header, fmt.Sprintf("%s_%s", id, column.name))
+		}
+	}
+	for i := 0; i < len(webServers); i++ {
+		header = append(header, fmt.Sprintf("%s_Session", webServers[i].ID))
+	}
+
+	// Add here for CSV output if parameters increase
+	csvData.WriteString(strings.Join(header, ",") + "\n")
+
+	rowCount := len(response.CurrentQueue)
+
+	for i := 0; i < rowCount; i++ {
+		record := []string{fmt.Sprint(response.TotalQueue[i])}
+		record = append(record, strconv.Itoa(response.CurrentQueue[i]))
+		record = append(record, strconv.Itoa(response.FirstReceivedQueue[i]))
+		record = append(record, strconv.Itoa(response.SecondReceivedQueue[i]))
+		record = append(record, strconv.Itoa(response.CurrentResponse[i]))
+		record = append(record, strconv.Itoa(response.CurrentTransport[i]))
+		record = append(record, strconv.Itoa(response.InFlight[i]))
+		record = append(record, formatFloat(response.ArrivalRate[i]))
+		record = append(record, formatFloat(response.Latency[i]))
+		record = append(record, formatFloat(response.ServiceRate[i]))
+		record = append(record, formatFloat(response.ServerRate[i]))
+		record = append(record, formatFloat(response.Wait[i]))
+		record = append(record, formatFloat(response.GossipMean[i]))
+		record = append(record, formatFloat(response.GossipCount[i]))
+		record = append(record, formatFloat(response.GossipCountError[i]))
+		record = append(record, formatFloat(response.GossipSpread[i]))
+
+		samples := response.Neighbors[i]
+		for _, column := range neighborColumns {
+			for _, id := range ids {
+				record = append(record, neighborValue(samples, id, column.value))
+			}
+		}
+		for j := 0; j < len(webServers); j++ {
+			if i < len(backends[j].Session) {
+				record = append(record, strconv.Itoa(backends[j].Session[i]))
+			} else {
+				record = append(record, "0")
+			}
+		}
+		csvData.WriteString(strings.Join(record, ",") + "\n")
+	}
+
+	_, err = file.WriteString(csvData.String())
+	if err != nil {
+		fmt.Println("Error writing to CSV file:", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename="+config.LogFile)
+
+	http.ServeFile(w, r, config.LogFile)
+
+	final = true
+}
+
+// Columns recorded for every adjacent LB (<id>_<name>), in order
+var neighborColumns = []struct {
+	name  string
+	value func(neighborSample) string
+}{
+	{"Data", func(v neighborSample) string { return strconv.Itoa(v.Data) }},
+	{"Estimated", func(v neighborSample) string { return formatFloat(v.Estimated) }},
+	{"Weight", func(v neighborSample) string { return strconv.Itoa(v.Weight) }},
+	{"Transport", func(v neighborSample) string { return strconv.Itoa(v.Transport) }},
+	{"InTransit", func(v neighborSample) string { return strconv.Itoa(v.InTransit) }},
+	{"Kappa", func(v neighborSample) string { return strconv.FormatFloat(v.Kappa, 'f', 4, 64) }},
+	{"Degree", func(v neighborSample) string { return strconv.Itoa(v.Degree) }},
+	{"Flow", func(v neighborSample) string { return formatFloat(v.Flow) }},
+	{"Tokens", func(v neighborSample) string { return formatFloat(v.Tokens) }},
+	{"LastFlow", func(v neighborSample) string { return formatFloat(v.LastFlow) }},
+	{"PIDP", func(v neighborSample) string { return formatFloat(v.PID.P) }},
+	{"PIDI", func(v neighborSample) string { return formatFloat(v.PID.I) }},
+	{"PIDD", func(v neighborSample) string { return formatFloat(v.PID.D) }},
+	{"Matches", func(v neighborSample) string { return strconv.Itoa(v.Matches) }},
+	{"Distance", func(v neighborSample) string { return strconv.Itoa(v.Distance) }},
+	{"GossipMean", func(v neighborSample) string { return formatFloat(v.GossipMean) }},
+	{"Limited", func(v neighborSample) string { return strconv.Itoa(v.Limited) }},
+	{"Mode", func(v neighborSample) string { return strconv.FormatBool(v.Mode.On) }},
+	{"ModeChanges", func(v neighborSample) string { return strconv.Itoa(v.Mode.Changes) }},
+	{"State", func(v neighborSample) string { return v.State.String() }},
+	{"StateChanges", func(v neighborSample) string { return strconv.Itoa(v.StateChanges) }},
+	{"Capacity", func(v neighborSample) string { return formatFloat(v.Capacity) }},
+	{"InFlight", func(v neighborSample) string { return strconv.Itoa(v.InFlight) }},
+	{"ArrivalRate", func(v neighborSample) string { return formatFloat(v.ArrivalRate) }},
+	{"Latency", func(v neighborSample) string { return formatFloat(v.Latency) }},
+	{"ServiceRate", func(v neighborSample) string { return formatFloat(v.ServiceRate) }},
+	{"ServerRate", func(v neighborSample) string { return formatFloat(v.ServerRate) }},
+	{"Wait", func(v neighborSample) string { return formatFloat(v.Wait) }},
+	{"RTT", func(v neighborSample) string { return formatMillis(v.RTT) }},
+	{"Seq", func(v neighborSample) string { return strconv.FormatUint(v.Seq, 10) }},
+	{"Delay", func(v neighborSample) string { return formatMillis(v.Delay) }},
+	{"Age", func(v neighborSample) string { return formatMillis(v.Age) }},
+	{"Reordered", func(v neighborSample) string { return strconv.Itoa(v.Reordered) }},
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+// Duration in ms
+func formatMillis(d time.Duration) string {
+	return formatFloat(float64(d) / float64(time.Millisecond))
+}
+
+// Value of an adjacent LB at one tick, or empty when it was not adjacent
+func neighborValue(samples map[string]neighborSample, id string, value func(neighborSample) string) string {
+	v, ok := samples[id]
+	if !ok {
+		return ""
+	}
+	return value(v)
+}
+
+// Routes of the data port: the CSV export and the admin endpoint. The
+// export ends the LB, so it answers only GET of the root itself; other
+// paths get 404 and other methods 405.
+func dataMux() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/neighbors", neighborsHandler)
+	mux.HandleFunc("GET /{$}", dataReceiver)
+	return mux
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	dcv1 "custome_weightedRR/api/dc/v1"
+	"custome_weightedRR/api/legacy"
+)
+
+type Server struct {
+	dcv1.UnimplementedDiffusionControlServer
+}
+
+// gRPC Server
+func gRPC_Server() {
+	defer wg.Done()
+
+	lis, err := net.Listen("tcp", listenOn(ownGRPC))
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+	s := grpc.NewServer(keepaliveServerOptions()...)
+	dcv1.RegisterDiffusionControlServer(s, &Server{})
+	if config.LegacyAPI {
+		legacy.RegisterLoadBalancerServer(s, &legacyServer{})
+	}
+	log.Printf("gRPC Server listening at %v", lis.Addr())
+	if err := s.Serve(lis); err != nil {
+		log.Fatalf("Failed to serve: %v", err)
+	}
+}
+
+// Health check to adjacent LBs
+func (s *Server) Health(ctx context.Context, req *dcv1.HealthRequest) (*dcv1.HealthResponse, error) {
+	return &dcv1.HealthResponse{Healthy: true, NodeId: ownNodeID}, nil
+}
+
+// Apply every report of an adjacent LB and answer it with the own one
+func (s *Server) Feedback(stream dcv1.DiffusionControl_FeedbackServer) error {
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			log.Printf("Error receiving control message: %v", err)
+			return err
+		}
+
+		mutex.Lock()
+		if num := neighborIndex(in.NodeId); num >= 0 && applyReport(num, in) {
+			policy.Calculate(neighborLoad(num), num)
+		}
+		mutex.Unlock()
+
+		// Send current control information to the client
+		if err := stream.Send(localReport(in.NodeId, true)); err != nil {
+			log.Printf("Error sending response: %v", err)
+			return err
+		}
+	}
+}
+
+// Own load report to the central controller
+func (s *Server) Snapshot(ctx context.Context, req *dcv1.SnapshotRequest) (*dcv1.LoadReport, error) {
+	return localReport("", true), nil
+}
+
+// Weights of the adjacent LBs computed by the central controller; they are
+// applied only with the central policy
+func (s *Server) SetWeights(ctx context.Context, req *dcv1.SetWeightsRequest) (*dcv1.SetWeightsResponse, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	c, ok := policy.(*centralControl)
+	if ok {
+		c.setWeights(req)
+	}
+	return &dcv1.SetWeightsResponse{Applied: ok}, nil
+}
+
+// Feedback stream to an adjacent LB, whichever API version it speaks
+type feedbackStream interface {
+	Send(*dcv1.LoadReport) error
+	Recv() (*dcv1.LoadReport, error)
+}
+
+// API versions of the feedback, recorded per adjacent LB
+const (
+	apiV1     = "dc.v1"
+	apiLegacy = "legacy"
+)
+
+// Health check the adjacent LB and open the feedback stream with dc.v1,
+// or with the legacy API when the neighbor does not implement dc.v1
+func openFeedback(ctx context.Context, conn *grpc.ClientConn, id string) (feedbackStream, string, error) {
+	client := dcv1.NewDiffusionControlClient(conn)
+
+	hctx, cancel := context.WithTimeout(ctx, downTimeout())
+	res, err := client.Health(hctx, &dcv1.HealthRequest{NodeId: ownNodeID})
+	cancel()
+	if status.Code(err) == codes.Unimplemented && config.LegacyAPI {
+		return openLegacyFeedback(ctx, conn)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("health check failed: %v", err)
+	}
+	if !res.Healthy {
+		return nil, "", fmt.Errorf("not healthy")
+	}
+	if res.NodeId != id {
+		log.Printf("Warning: neighbor %s identifies itself as %q", id, res.NodeId)
+	}
+
+	stream, err := client.Feedback(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating stream: %v", err)
+	}
+	return stream, apiV1, nil
+}
+
+// gRPC Client
+// Supervises the feedback of one adjacent LB until ctx is canceled (the
+// neighbor is removed), reconnecting with exponential backoff
+func gRPC_Client(ctx context.Context, id string, address string) {
+	defer wg.Done()
+
+	var b backoff
+	b.reset()
+	for {
+		if connectFeedback(ctx, id, address) {
+			b.reset()
+		}
+		setState(ctx, id, stateDown)
+
+		if ctx.Err() == nil {
+			log.Printf("Reconnecting to %s in %v", address, b.next)
+		}
+		if !b.wait(ctx) {
+			log.Printf("Feedback to %s stopped", address)
+			return
+		}
+	}
+}
+
+// Connect to the adjacent LB and run the feedback stream until it fails.
+// Reports whether the neighbor was healthy at some point.
+func connectFeedback(ctx context.Context, id string, address string) bool {
+	// Establish connection with the server
+	dctx, cancel := context.WithTimeout(ctx, downTimeout())
+	defer cancel()
+	options := append([]grpc.DialOption{grpc.WithInsecure(), grpc.WithBlock()}, keepaliveDialOptions()...)
+	conn, err := grpc.DialContext(dctx, address, options...)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("No connect to %s: %v", address, err)
+		}
+		return false
+	}
+	defer conn.Close()
+
+	// The stream ends when this connection is given up
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, api, err := openFeedback(sctx, conn, id)
+	if err != nil {
+		log.Printf("Load Balancer at %s is down: %v", address, err)
+		return false
+	}
+	log.Printf("Feedback to %s uses %s", address, api)
+	setAPI(ctx, id, api)
+	setState(ctx, id, stateHealthy)
+	handleControlStream(sctx, stream, address, id)
+	return true
+}
+
+func handleControlStream(ctx context.Context, stream feedbackStream, address string, id string) {
+	// From here, processing when health check returns true
+	// Responses are received in the background so that unanswered rounds can be counted
+	responses := make(chan *dcv1.LoadReport)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			in, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case responses <- in:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Periodically send control information and count the rounds without a response
+	state := stateHealthy
+	missed, answered := 0, 0
+	pending := false
+	var sentAt time.Time
+	ticker := time.NewTicker(time.Duration(config.Feedback) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case err := <-recvErr:
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Error receiving control response: %v", err)
+			if status.Code(err) == codes.Canceled || status.Code(err) == codes.Unavailable {
+				log.Printf("Receive Connection to %s was lost, reconnecting...", address)
+			}
+			return
+
+		case in := <-responses:
+			// Without missed rounds the response answers the last report
+			rtt := time.Duration(0)
+			if pending && missed == 0 {
+				rtt = time.Since(sentAt)
+			}
+			pending = false
+			missed = 0
+			answered++
+			if state == stateSuspect && answered >= config.RecoverAfter {
+				state = stateHealthy
+				setState(ctx, id, state)
+			}
+
+			mutex.Lock()
+			// The neighbor may have been removed while waiting for the response
+			if num := neighborIndex(id); num >= 0 && ctx.Err() == nil {
+				if rtt > 0 {
+					observeRTT(num, rtt)
+				}
+				if applyReport(num, in) {
+					policy.Calculate(neighborLoad(num), num)
+				}
+			}
+			mutex.Unlock()
+
+		case <-ticker.C:
+			if pending {
+				missed++
+				answered = 0
+				if missed >= config.DownAfter {
+					log.Printf("No control response from %s in %d rounds, reconnecting...", address, missed)
+					return
+				}
+				if missed >= config.SuspectAfter && state == stateHealthy {
+					state = stateSuspect
+					setState(ctx, id, state)
+				}
+			}
+
+			// Send control information
+			sentAt = time.Now()
+			if err := stream.Send(localReport(id, false)); err != nil {
+				if status.Code(err) == codes.Canceled || status.Code(err) == codes.Unavailable {
+					log.Printf("Send Connection to %s was lost, reconnecting...", address)
+				}
+				return
+			}
+			pending = true
+		}
+	}
+}
+
+func setAPI(ctx context.Context, id string, api string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if num := neighborIndex(id); num >= 0 && ctx.Err() == nil {
+		clusterLBs[num].API = api
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	dcv1 "custome_weightedRR/api/dc/v1"
+)
+
+// Push-sum gossip of the global mean load and the number of clusters over
+// the feedback reports (guarded by mutex). Every feedback interval an LB
+// keeps 1/(d+1) of its mass and sends 1/(d+1) to each of its d neighbors
+// speaking dc.v1. The mass is (queue, 1) for the mean load and (1, 1 at the
+// leader else 0) for the number of clusters; the ratios converge to the
+// mean and the count on a connected graph.
+//
+// The mass sent to a neighbor is carried as a running total of the epoch,
+// so that lost, repeated or reordered reports do not lose or duplicate mass.
+// Epochs of gossip_epoch ms follow the wall clock, so that all LBs restart
+// from their current loads together; an LB that sees a newer epoch in a
+// report joins it right away.
+type gossipMass struct {
+	Load, Weight  float64
+	Count, Leader float64
+}
+
+func (m gossipMass) add(o gossipMass) gossipMass {
+	return gossipMass{m.Load + o.Load, m.Weight + o.Weight, m.Count + o.Count, m.Leader + o.Leader}
+}
+
+func (m gossipMass) sub(o gossipMass) gossipMass {
+	return gossipMass{m.Load - o.Load, m.Weight - o.Weight, m.Count - o.Count, m.Leader - o.Leader}
+}
+
+func (m gossipMass) scale(f float64) gossipMass {
+	return gossipMass{m.Load * f, m.Weight * f, m.Count * f, m.Leader * f}
+}
+
+var (
+	gossipEpoch   int64      // Current epoch
+	gossipStarted time.Time  // Start of the current epoch at this LB
+	gossipSplit   time.Time  // Last split of the mass
+	mass          gossipMass // Mass kept by this LB
+
+	// Estimates of the last completed epoch, used until the current one has
+	// run for half an epoch
+	lastMean, lastCount float64
+	haveLast            bool
+)
+
+func gossipEnabled() bool {
+	return gossipEpochLength() > 0
+}
+
+// Length of an epoch [ms], 0 without gossip. gossip_epoch 0 disables the
+// gossip, except with the mean policy, which needs it and then uses
+// max(2000, 4 feedback intervals).
+func gossipEpochLength() int {
+	if config.GossipEpoch == 0 && config.Policy == "mean" {
+		return max(2000, 4*config.Feedback)
+	}
+	return config.GossipEpoch
+}
+
+func epochOf(now time.Time) int64 {
+	return now.UnixMilli() / int64(gossipEpochLength())
+}
+
+// Restart the sums from the current load
+func startEpoch(epoch int64, now time.Time) {
+	if gossipEpoch != 0 {
+		lastMean, lastCount = currentEstimate()
+		haveLast = true
+	}
+	gossipEpoch, gossipStarted = epoch, now
+	mass = gossipMass{Load: float64(queue), Weight: 1, Count: 1}
+	if ownNodeID == config.Leader {
+		mass.Leader = 1
+	}
+	for i := range clusterLBs {
+		clusterLBs[i].GossipSent = gossipMass{}
+		clusterLBs[i].GossipRecv = gossipMass{}
+	}
+}
+
+// Advance the epoch and split the mass once per feedback interval
+func gossipTick(now time.Time) {
+	if epoch := epochOf(now); epoch > gossipEpoch {
+		startEpoch(epoch, now)
+	}
+	if now.Sub(gossipSplit) < time.Duration(config.Feedback)*time.Millisecond {
+		return
+	}
+	gossipSplit = now
+
+	var peers []int
+	for i, lb := range clusterLBs {
+		if lb.IsHealthy && lb.API == apiV1 {
+			peers = append(peers, i)
+		}
+	}
+	share := mass.scale(1 / float64(len(peers)+1))
+	for _, i := range peers {
+		clusterLBs[i].GossipSent = clusterLBs[i].GossipSent.add(share)
+	}
+	mass = share
+}
+
+// Gossip part of the report to clusterLBs[num]
+func gossipOut(num int, now time.Time) *dcv1.GossipMass {
+	gossipTick(now)
+	sent := clusterLBs[num].GossipSent
+	mean, _ := gossipEstimate(now)
+	return &dcv1.GossipMass{
+		Epoch:  gossipEpoch,
+		Load:   sent.Load,
+		Weight: sent.Weight,
+		Count:  sent.Count,
+		Leader: sent.Leader,
+		Mean:   mean,
+	}
+}
+
+// Take the mass newly sent by clusterLBs[num]
+func gossipIn(num int, g *dcv1.GossipMass, now time.Time) {
+	lb := &clusterLBs[num]
+	lb.GossipMean = g.Mean
+	if g.Epoch > gossipEpoch {
+		startEpoch(g.Epoch, now)
+	}
+	if g.Epoch < gossipEpoch {
+		return
+	}
+	total := gossipMass{g.Load, g.Weight, g.Count, g.Leader}
+	mass = mass.add(total.sub(lb.GossipRecv))
+	lb.GossipRecv = total
+}
+
+// Ratios of the current epoch; the count is 0 until mass of the leader has arrived
+func currentEstimate() (mean, count float64) {
+	if mass.Weight > 0 {
+		mean = mass.Load / mass.Weight
+	}
+	if mass.Leader > 0 {
+		count = mass.Count / mass.Leader
+	}
+	return mean, count
+}
+
+// Estimate of the global mean queue and the number of clusters
+func gossipEstimate(now time.Time) (mean, count float64) {
+	if !haveLast || now.Sub(gossipStarted) >= time.Duration(gossipEpochLength())*time.Millisecond/2 {
+		return currentEstimate()
+	}
+	return lastMean, lastCount
+}
+
+// Largest difference between the own estimate of the mean and those of the
+// neighbors; it vanishes when the gossip has converged
+func gossipSpread(mean float64) float64 {
+	spread := 0.0
+	for _, lb := range clusterLBs {
+		if lb.IsHealthy && lb.API == apiV1 {
+			spread = max(spread, math.Abs(mean-lb.GossipMean))
+		}
+	}
+	return spread
+}
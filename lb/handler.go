@@ -0,0 +1,144 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Handle requests according to the selected forwarding policy
+func lbHandler(w http.ResponseWriter, r *http.Request) {
+	arrived := time.Now()
+	mutex.Lock()
+	totalQueue++
+	queue++ // Increment the number of pending sessions
+	activeSessions.WithLabelValues(ownNodeID, ownClusterLB).Inc()
+	totalRequests.WithLabelValues(ownNodeID, ownClusterLB).Inc()
+
+	// X-Original-LB carries the node ID of the forwarding LB
+	originalLB := r.Header.Get("X-Original-LB")
+	if originalLB == "" {
+		// fmt.Println("source: external user")
+	} else if originalLB == config.Leader {
+		firstReceivedCount++
+	} else {
+		adjacentQueueCount++
+	}
+
+	// X-Hops counts how many times the request has been forwarded
+	hops, _ := strconv.Atoi(r.Header.Get("X-Hops"))
+
+	// Ask the policy whether to forward, and to which adjacent LB
+	var forward bool
+	if t, ok := policy.(transitRouter); ok && originalLB != "" {
+		forward = t.Transit(originalLB, hops)
+	} else {
+		forward = policy.Forward()
+	}
+	next := -1
+	if forward {
+		next = selectWithinRate(time.Now())
+	}
+
+	proxyURL := &url.URL{
+		Scheme: "http",
+		Host:   "",
+	}
+	nextID := ""
+	if next >= 0 {
+		clusterLBs[next].Transport++
+		clusterLBs[next].InTransit++
+		proxyURL.Host = clusterLBs[next].Address
+		nextID = clusterLBs[next].ID
+	} else {
+		backend := RoundRobin_Backend()
+		proxyURL.Host = backend.Address
+		inFlight++
+	}
+	mutex.Unlock()
+	start := time.Now()
+
+	proxy := httputil.NewSingleHostReverseProxy(proxyURL)
+	proxy.Transport = transportSet
+
+	if next >= 0 {
+		originalDirector := proxy.Director
+		proxy.Director = func(req *http.Request) {
+			originalDirector(req)
+			req.Header.Set("X-Original-LB", ownNodeID)
+			req.Header.Set("X-Hops", strconv.Itoa(hops+1))
+		}
+
+		proxy.ModifyResponse = func(res *http.Response) error {
+			// The round trip to the neighbor is the response time without
+			// the time the request spent at the neighbor
+			rtt := time.Duration(-1)
+			if served, err := strconv.ParseInt(res.Header.Get(servedInHeader), 10, 64); err == nil {
+				rtt = time.Since(start) - time.Duration(served)*time.Microsecond
+			}
+			mutex.Lock()
+			activeSessions.WithLabelValues(ownNodeID, ownClusterLB).Dec()
+			queue-- // Decrement the number of pending sessions after processing
+			currentTransport++
+			if num := neighborIndex(nextID); num >= 0 && rtt > 0 {
+				observeRTT(num, rtt)
+			}
+			mutex.Unlock()
+			setServedIn(res, arrived)
+			return nil
+		}
+	} else {
+		// Rewrite the response -> when sending to internal web servers
+		proxy.ModifyResponse = func(res *http.Response) error {
+			mutex.Lock()
+			activeSessions.WithLabelValues(ownNodeID, ownClusterLB).Dec()
+			queue-- // Decrement the number of pending sessions after processing
+			responseCount++
+			inFlight--
+			observeLatency(time.Since(start))
+			mutex.Unlock()
+			setServedIn(res, arrived)
+			return nil
+		}
+	}
+	// Undo the accounting of the request when the backend or the adjacent
+	// LB cannot be reached, since ModifyResponse is never called then
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		mutex.Lock()
+		activeSessions.WithLabelValues(ownNodeID, ownClusterLB).Dec()
+		queue--
+		if next < 0 {
+			inFlight--
+		} else if num := neighborIndex(nextID); num >= 0 {
+			// The neighbor may have moved in clusterLBs or reported since
+			clusterLBs[num].Transport--
+			clusterLBs[num].InTransit = max(clusterLBs[num].InTransit-1, 0)
+		}
+		mutex.Unlock()
+		log.Printf("Proxy to %s failed: %v", proxyURL.Host, err)
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// X-Served-In carries the time from the arrival of a request at the LB to
+// its response [µs], so that the forwarding LB can tell the round trip of
+// the link from the response time
+const servedInHeader = "X-Served-In"
+
+func setServedIn(res *http.Response, arrived time.Time) {
+	res.Header.Set(servedInHeader, strconv.FormatInt(time.Since(arrived).Microseconds(), 10))
+}
+
+// Round Robin within the cluster (distribution to backend servers)
+// Must be called with mutex held
+func RoundRobin_Backend() webServer {
+	webServers[currentIndex].Sessions++
+	list := webServers[currentIndex]
+	currentIndex = (currentIndex + 1) % len(webServers)
+
+	return list
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// State of an adjacent LB, driven by its feedback stream
+//
+//	down    -> healthy  connected and the health check succeeded
+//	healthy -> suspect  suspect_after feedback rounds were not answered
+//	suspect -> healthy  recover_after rounds were answered again
+//	suspect -> down     down_after rounds were not answered (reconnect)
+//	any     -> down     the connection or the stream failed (reconnect)
+//
+// Requests are only forwarded to healthy neighbors.
+type neighborState int
+
+const (
+	stateDown neighborState = iota
+	stateSuspect
+	stateHealthy
+)
+
+func (s neighborState) String() string {
+	switch s {
+	case stateDown:
+		return "down"
+	case stateSuspect:
+		return "suspect"
+	case stateHealthy:
+		return "healthy"
+	}
+	return fmt.Sprintf("neighborState(%d)", int(s))
+}
+
+func (s neighborState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Change the state of an adjacent LB. Ignored once ctx is canceled, so that a
+// stopped supervisor does not touch a neighbor added again with the same ID.
+func setState(ctx context.Context, id string, state neighborState) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	num := neighborIndex(id)
+	if num < 0 || clusterLBs[num].State == state {
+		return
+	}
+
+	log.Printf("Neighbor %s: %s -> %s", id, clusterLBs[num].State, state)
+	clusterLBs[num].State = state
+	clusterLBs[num].IsHealthy = state == stateHealthy
+	clusterLBs[num].StateChanges++
+	neighborStates.WithLabelValues(ownNodeID, id).Set(float64(state))
+	neighborStateChanges.WithLabelValues(ownNodeID, id, state.String()).Inc()
+}
+
+// Time without answers after which an adjacent LB is down; also bounds
+// the dial and the health check
+func downTimeout() time.Duration {
+	return time.Duration(config.Feedback*config.DownAfter) * time.Millisecond
+}
+
+// Exponential backoff between reconnection attempts
+type backoff struct {
+	next time.Duration
+}
+
+func (b *backoff) reset() {
+	b.next = time.Duration(config.ReconnectMin) * time.Millisecond
+}
+
+// Wait for the next attempt; reports false when ctx is canceled meanwhile
+func (b *backoff) wait(ctx context.Context) bool {
+	timer := time.NewTimer(b.next)
+	defer timer.Stop()
+
+	b.next *= 2
+	if limit := time.Duration(config.ReconnectMax) * time.Millisecond; b.next > limit {
+		b.next = limit
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// gRPC keepalive of the feedback connections (disabled with keepalive: 0)
+func keepaliveDialOptions() []grpc.DialOption {
+	if config.Keepalive == 0 {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                time.Duration(config.Keepalive) * time.Millisecond,
+		Timeout:             downTimeout(),
+		PermitWithoutStream: true,
+	})}
+}
+
+// Accept the keepalive pings of the adjacent LBs
+func keepaliveServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+		MinTime:             minKeepalive / 2,
+		PermitWithoutStream: true,
+	})}
+}
+
+// gRPC clients do not ping more often than this
+const minKeepalive = 10 * time.Second
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"custome_weightedRR/topology"
+)
+
+// Find the node whose cluster_lb host is one of the local interface addresses.
+// Used only when no node ID is configured; more than one match is an error.
+func detectNodeID(topo topology.Topology, ports topology.Ports) (string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	local := make(map[string]bool)
+	for _, a := range addrs {
+		if ipNet, ok := a.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+			local[ipNet.IP.String()] = true
+		}
+	}
+
+	var matches []string
+	for _, id := range topo.IDs() {
+		addr, err := topo.HTTPAddr(id, ports)
+		if err != nil {
+			continue
+		}
+		host, _, _ := net.SplitHostPort(addr)
+		if ip := net.ParseIP(host); ip != nil && local[ip.String()] {
+			matches = append(matches, id)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no cluster_lb matches the local addresses")
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("several nodes match the local addresses: %s", strings.Join(matches, ", "))
+	}
+}
@@ -1,818 +0,0 @@
-// Least Connection method (Operation not guaranteed)
-package main
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"flag"
-	"io"
-	"io/ioutil"
-	"log"
-	"math"
-	"math/rand"
-	"net"
-	"net/http"
-	"net/http/httputil"
-	"net/url"
-	"os"
-	"os/exec"
-	"strings"
-	"strconv"
-	"sync"
-	"time"
-	"sort"
-
-	"github.com/redis/go-redis/v9"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-
-	pb "custome_weightedRR/api"
-)
-
-type LoadBalancer struct {
-	ID int
-	Address string
-	IsHealthy bool 
-	Data int
-	Weight int
-	Transport int
-}
-
-type webServer struct {
-	ID int
-	IP     string
-	Weight int
-	Sessions int
-}
-
-type ClusterJSON struct {
-	AdjacentList map[string]string `json:"adjacentList"`
-	InternalList map[string]string `json:"internalList"`
-}
-
-type Server struct {
-	pb.UnimplementedLoadBalancerServer
-}
-
-type Response struct {
-	TotalQueue []int 
-	CurrentQueue []int 
-	FirstReceivedQueue []int
-	SecondReceivedQueue []int
-	CurrentResponse []int 
-	CurrentTransport []int 
-	Data []int
-	Weight []int
-	Transport []int
-	Session []int
-}
-
-type splitData struct {
-	Data []int
-	Weight []int
-	Transport []int
-}
-
-type splitWebServer struct {
-	Session []int
-}
-
-var (
-	clusterLBs []LoadBalancer
-	webServers []webServer
-	ownWebServers []string
-	randomIndex webServer
-	adjacentIndex int
-
-	wg sync.WaitGroup
-	mutex sync.RWMutex
-	rrTieMu sync.Mutex
-
-	ctx        = context.Background()
-	redisClient *redis.Client
-	totalLBs int
-	currentIndex int 
-	ownClusterLB string
-	isLeader bool
-	flushOnStartup = false
-	isTransport bool
-
-	firstRecievedIP string
-	leaderLB string
-
-	// redis client (IP address is statically configured.)
-	rdb = redis.NewClient(&redis.Options{
-		Addr: "172.18.4.7:6379",
-		DB:   0,
-	})
-
-	transportSet = &http.Transport{
-		MaxIdleConns:        1000,
-		MaxIdleConnsPerHost: 1000,
-		IdleConnTimeout:     90 * time.Second,
-	}
-
-	// Evaluation parameters
-	queue        int // Number of pending TCP sessions
-	totalQueue int // Total number of requests received by the LB
-	responseCount    int // Count of responses returned	
-	webResponseCount int // Count of responses returned by internal web servers
-	currentTransport int // Number of requests forwarded to adjacent LBs via reverse proxy
-	firstReceivedCount int // Number of requests directly received from adjacent LBs
-	adjacentQueueCount int // Number of multi-hop requests
-
-	totalData []int 
-	currentQueue []int
-	firstReceivedQueue []int
-	secondReceivedQueue []int
-	currentResponse []int
-	totalTransport []int
-
-	// Feedback information obtained from adjacent LBs
-	data []int
-	weight []int
-	transport []int
-	session []int
-
-	final bool
-	feedback int
-	threshold int
-	kappa float64
-)	
-
-const (
-	tcpPort   string  = ":8001"
-	subPort   string  = ":8002"
-	dstPort   string  = ":80"    
-	grpcPort  string  = ":50051" 
-	sleepTime time.Duration = 1
-	getDataTime time.Duration = 100
-
-	redisHost  = "172.18.4.22:6379" 
-	redisKey   = "ready:"
-	syncChan   = "sync_start"
-	logFile = "./log/output.csv"
-)
-
-func init(){
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run main.go <positional> -t <value> -q <value> -k <value>")
-		os.Exit(1)
-	}
-	positionalArg := os.Args[1]
-
-	clusterNum, err := strconv.Atoi(positionalArg)
-	if err != nil {
-		fmt.Println("Invalid positional argument:", positionalArg)
-		os.Exit(1)
-	}
-
-	flagSet := flag.NewFlagSet("args", flag.ExitOnError)
-	
-	var t, q int
-	var k float64
-
-	flagSet.IntVar(&t, "t", 0, "feedback information")
-	flagSet.IntVar(&q, "q", 0, "threshold")
-	flagSet.Float64Var(&k, "k", 0.0, "diffusion coefficient")
-
-	flagSet.Parse(os.Args[2:])
-	feedback = t
-    threshold = q
-    kappa = k
-
-	fmt.Printf("Cluster Number: %d\n", clusterNum)
-	fmt.Printf("feedback -t : %d\n", feedback)
-    fmt.Printf("threshold -q : %d\n", threshold)
-    fmt.Printf("kappa -k : %.2f\n", kappa)
-
-	file, err := os.Open("./json/adjacentList.json")
-	if err != nil {
-		log.Fatalf("Failed to open file: %v", err)
-	}
-	defer file.Close()
-
-	value, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Fatalf("Failed to read file: %v", err)
-	}
-
-	clusters := make(map[string]ClusterJSON)
-	err = json.Unmarshal(value, &clusters)
-	if err != nil {
-		log.Fatal(err)
-	}
-	totalLBs = len(clusters)
-
-	cmd := exec.Command("hostname", "-i")
-	output, err := cmd.Output()
-	if err != nil {
-		panic(err)
-	}
-
-	ipAddresses := strings.Fields(string(output))
-	if strings.HasPrefix(ipAddresses[0], "172.") {
-		ownClusterLB = ipAddresses[0]
-	} else {
-		ownClusterLB = ipAddresses[1]
-	}
-
-	var id, idWeb int
-	for _, cluster := range clusters {
-		clusterLBIP := cluster.InternalList["cluster_lb"]
-		if clusterLBIP == ownClusterLB {
-			lastOctet := getLastOctet(clusterLBIP)
-			if (lastOctet - clusterNum) == 2 {
-				firstRecievedIP = clusterLBIP
-				leaderLB = clusterLBIP
-			}
-			fmt.Println(clusterLBIP, ownClusterLB, firstRecievedIP, leaderLB)
-			for _, v := range cluster.AdjacentList {
-				clusterLBs = append(clusterLBs, LoadBalancer{
-					ID:        id,
-					Address:   v,
-					IsHealthy: true,
-					Data:      0,
-					Weight:    0,
-					Transport: 0,
-				})
-				id++
-			}
-			for k, v := range cluster.InternalList {
-				if strings.HasPrefix(k, "web") {
-					ownWebServers = append(ownWebServers, v)
-				}
-			}
-		}
-	}
-
-	for _, ip := range ownWebServers {
-		webServers = append(webServers, webServer{
-			ID:       idWeb,
-			IP:       ip,
-			Weight:   0,
-			Sessions: 0,
-		})
-		idWeb++
-	}
-
-	sort.Slice(clusterLBs, func(i, j int) bool {
-		return getLastOctet(clusterLBs[i].Address) < getLastOctet(clusterLBs[j].Address)
-	})
-	sort.Slice(webServers, func(i, j int) bool {
-		return getLastOctet(webServers[i].IP) < getLastOctet(webServers[j].IP)
-	})
-
-	for i := range clusterLBs {
-		getLastOctet(clusterLBs[i].Address)
-		clusterLBs[i].ID = i
-	}
-	for i := range webServers {
-		lastOctet := getLastOctet(webServers[i].IP)
-		webServers[i].ID = lastOctet % 10
-	}
-
-	fmt.Println(clusterLBs, ownWebServers, webServers)
-
-	isLeader = ownClusterLB == leaderLB // Only the leader LB is set to true
-	waitForAllLBsAndSyncStart(ctx, rdb, ownClusterLB, totalLBs, isLeader, "lb_ready:")
-}
-
-func main(){
-	wg.Add(1)
-	go gRPC_Server()
-
-	for i, address := range clusterLBs {
-		wg.Add(1)
-		go gRPC_Client(address.Address, i) 
-	}
-
-	wg.Add(1)
-	go func(){
-		defer wg.Done()
-		s := http.Server{
-			Addr:    tcpPort,
-			Handler: http.HandlerFunc(lbHandler),
-		}
-
-		fmt.Printf("HTTP server is listening on %s...\n", tcpPort)
-		if err := s.ListenAndServe(); err != nil {
-			log.Fatal(err.Error())
-		}
-	}()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		s := http.Server{
-			Addr:    subPort,
-			Handler: http.HandlerFunc(dataReceiver),
-		}
-
-		fmt.Printf("HTTP server is listening on %s...\n", subPort)
-		if err := s.ListenAndServe(); err != nil {
-			log.Fatal(err.Error())
-		}
-	}()
-
-	wg.Add(1)
-	go func(){
-		defer wg.Done()
-		for {
-			if final {
-				os.Exit(1)
-			}
-	
-			totalData = append(totalData, totalQueue)
-			currentQueue = append(currentQueue, queue)
-			firstReceivedQueue = append(firstReceivedQueue, firstReceivedCount)
-			secondReceivedQueue = append(secondReceivedQueue, adjacentQueueCount)
-			currentResponse = append(currentResponse, responseCount)
-			totalTransport = append(totalTransport, currentTransport)
-	
-			for _, server := range clusterLBs {
-				data = append(data, server.Data)
-				weight = append(weight, server.Weight)
-				transport = append(transport, server.Transport)
-			}
-			for _, backend := range webServers {
-				session = append(session, backend.Sessions)
-			}
-
-			// fmt.Println(totalQueue, queue, responseCount, firstReceivedCount, adjacentQueueCount, currentTransport) // for debug
-			time.Sleep(getDataTime * time.Millisecond) // ms
-		}
-	}()
-
-	wg.Wait()
-}
-
-// Handle requests using LC
-func lbHandler(w http.ResponseWriter, r *http.Request) {
-	isTransport = false
-	mutex.Lock()
-	totalQueue++
-	queue++ // Increment the number of pending sessions
-
-	originalLB := r.Header.Get("X-Original-LB")
-	if originalLB == "" {
-		// fmt.Println("source: external user")
-	} else if originalLB == firstRecievedIP {
-		// fmt.Println("source LB address:", originalLB)
-		firstReceivedCount++
-	} else {
-		// fmt.Println("source adjacentLB address:", originalLB)
-		adjacentQueueCount++
-	}
-
-	mutex.Unlock()
-
-	proxyURL := &url.URL{
-		Scheme: "http",
-		Host:   "",
-	}
-
-	proxy := httputil.NewSingleHostReverseProxy(proxyURL)
-
-	if queue > threshold {
-		proxyURL.Host = LeastConn_AdjacentLB()
-
-		originalDirector := proxy.Director
-		proxy.Director = func(req *http.Request) {
-			originalDirector(req)
-			req.Header.Set("X-Original-LB", ownClusterLB)
-		}
-
-		proxy.ModifyResponse = func(res *http.Response) error {
-			mutex.Lock()
-			queue-- // Decrement after processing is complete
-			currentTransport++
-			mutex.Unlock()
-			return nil
-		}
-	} else {
-		randomIndex = RoundRobin_Backend()
-		proxyURL.Host = randomIndex.IP + dstPort
-
-		// Rewrite the response -> when sending to an internal web server
-		proxy.ModifyResponse = func(res *http.Response) error {
-			mutex.Lock()
-			queue-- // Decrement after processing is complete
-			responseCount++ 
-			mutex.Unlock()
-			return nil
-		}
-	}
-	proxy.ServeHTTP(w, r)
-}
-
-// Least Connection method (distribution to adjacent LBs)
-func LeastConn_AdjacentLB() string {
-    // Select the adjacent LB with IsHealthy true and the minimum Data (= current number of waiting sessions)
-    mutex.RLock()
-
-    // First, extract candidates (only those with IsHealthy true)
-    type candidate struct {
-        idx int
-        val int
-    }
-    var cands []candidate
-    for i, lb := range clusterLBs {
-        if lb.IsHealthy {
-            cands = append(cands, candidate{idx: i, val: lb.Data})
-        }
-    }
-    mutex.RUnlock()
-
-    // If all adjacent LBs have IsHealthy false (none of the adjacent LBs are available)
-    if len(cands) == 0 {
-        tempIndex := RoundRobin_Backend()
-		return tempIndex.IP + dstPort
-    }
-
-    // Find the minimum value and keep candidates with the same value
-    minVal := math.MaxInt
-    for _, c := range cands {
-        if c.val < minVal {
-            minVal = c.val
-        }
-    }
-    var minIdxs []int
-    for _, c := range cands {
-        if c.val == minVal {
-            minIdxs = append(minIdxs, c.idx)
-        }
-    }
-
-	log.Println("cands, minIdxs", cands, minIdxs)
-	
-	// // If there are multiple backends to transfer
-	// // select one from a Round-Robin
-	// rrTieMu.Lock()
-	// chosenIdxInMin := adjacentIndex % len(minIdxs)
-	// chosen := minIdxs[chosenIdxInMin]
-	// adjacentIndex++
-	// rrTieMu.Unlock()
-	// log.Println(chosenIdxInMin, chosen)
-
-	// select one from a Random
-	rand.Seed(time.Now().UnixNano()) 
-    chosen := minIdxs[0]
-    if len(minIdxs) > 1 {
-        chosen = minIdxs[rand.Intn(len(minIdxs))]
-    }
-
-	// Debug output
-    log.Printf("[LeastConn] chosen LB=%s(id=%d) data=%d transport=%d", clusterLBs[chosen].Address, clusterLBs[chosen].ID, clusterLBs[chosen].Data, clusterLBs[chosen].Transport)
-
-    clusterLBs[chosen].Transport++
-	return clusterLBs[chosen].Address + tcpPort
-}
-
-// Round Robin within the cluster (distribution to backend servers)
-func RoundRobin_Backend() webServer {
-	webServers[currentIndex].Sessions++
-	list := webServers[currentIndex]
-	currentIndex = (currentIndex + 1) % len(webServers)
-
-	return list
-}
-
-// gRPC Server
-func gRPC_Server() {
-	defer wg.Done()
-
-	lis, err := net.Listen("tcp", grpcPort)
-	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
-	}
-	s := grpc.NewServer()
-	pb.RegisterLoadBalancerServer(s, &Server{})
-	log.Printf("gRPC Server listening at %v", lis.Addr())
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
-	}
-}
-
-// Health check to adjacent LBs
-func (s *Server) GetBackendStatus(ctx context.Context, req *pb.BackendRequest) (*pb.BackendStatus, error) {
-	//fmt.Printf("Received health check request for server: %s\n", req.ServerName)
-	return &pb.BackendStatus{IsHealthy: true}, nil
-}
-
-// Send control information to adjacent LBs
-func (s *Server) ControlStream(stream pb.LoadBalancer_ControlStreamServer) error {
-	for {
-		_, err := stream.Recv()
-		if err == io.EOF {
-			return nil
-		}
-		if err != nil {
-			log.Printf("Error receiving control message: %v", err)
-			return err
-		}
-		// log.Printf("Received control command: %s, TCP Waiting Sessions: %d", in.Command, queue)
-
-		// Send current control information to the client
-		if err := stream.Send(&pb.ControlResponse{Status: "ok", Payload: int64(queue)}); err != nil {
-			log.Printf("Error sending response: %v", err)
-			return err
-		}
-	}
-}
-
-func healthCheck(client pb.LoadBalancerClient, adjacentLB string) bool {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(feedback) * time.Millisecond)
-	defer cancel()
-
-	req := &pb.BackendRequest{ServerName: "server-1"}
-	res, err := client.GetBackendStatus(ctx, req)
-	if err != nil || !res.IsHealthy {
-		log.Printf("Server %s is not healthy, trying the next one...", adjacentLB)
-		return false
-	}
-
-	// log.Printf("Server %s is healthy, starting control stream...", adjacent_lb)
-	return true
-}
-
-// gRPC Client
-func gRPC_Client(address string, i int) {
-	defer wg.Done()
-
-	adjacentLB := address + grpcPort
-
-	// Establish connection with the server
-	conn, err := grpc.Dial(adjacentLB, grpc.WithInsecure(), grpc.WithBlock())
-	if err != nil {
-		log.Fatalf("No connect: %v", err)
-	}
-	defer conn.Close()
-
-	client := pb.NewLoadBalancerClient(conn)
-
-	if healthCheck(client, adjacentLB) {
-		clusterLBs[i].IsHealthy = true
-		handleControlStream(client, adjacentLB, i)
-	} else {
-		clusterLBs[i].IsHealthy = false
-		log.Printf("Load Balancer at %s is down", adjacentLB)
-		return
-	}
-}
-
-func handleControlStream(client pb.LoadBalancerClient, address string, num int) {
-	//defer wg.Done()
-
-	// From here, process when health check is true
-	// Bidirectional streaming control information send/receive (create stream)
-	stream, err := client.ControlStream(context.Background())
-	if err != nil {
-		log.Fatalf("Error creating stream: %v", err)
-		// log.Printf("Error creating stream: %v", err)
-		return
-	}
-
-	// Periodically perform health checks and send/receive control information
-	ticker := time.NewTicker(time.Duration(feedback) * time.Millisecond)
-	for range ticker.C {
-		// Send control information
-		if err := stream.Send(&pb.ControlMessage{Command: "update_policy", Payload: int64(queue)}); err != nil {
-			// log.Printf("Error sending control message: %v", err)
-			clusterLBs[num].IsHealthy = false
-
-			if status.Code(err) == codes.Canceled || status.Code(err) == codes.Unavailable {
-				log.Printf("Send Connection to %s was lost, reconnecting...", address)
-				return
-			}
-			return
-		}
-
-		// Receive control information response
-		in, err := stream.Recv()
-		if err != nil {
-			log.Printf("Error receiving control response: %v", err)
-			clusterLBs[num].IsHealthy = false
-
-			if status.Code(err) == codes.Canceled || status.Code(err) == codes.Unavailable {
-				log.Printf("Receive Connection to %s was lost, reconnecting...", address)
-				return
-			}
-			return
-		}
-		// log.Printf("Received control response: %d", in.Payload)
-
-		mutex.Lock()
-		clusterLBs[num].Data = int(in.Payload)
-
-		mutex.Unlock()
-	}
-}
-
-func dataReceiver(w http.ResponseWriter, r *http.Request) {
-	// Obtain data for each parameter after the load test ends
-	fmt.Printf("total_request: %d\n", totalQueue)
-	fmt.Printf("queue_transition: %d\n", currentQueue)
-	fmt.Printf("total_data: %d\n", data)
-	fmt.Printf("total_weight: %d\n", weight)
-
-	for i := 0; i < len(clusterLBs); i++ {
-		fmt.Printf("amount of transport(%s): %d\n", clusterLBs[i].Address, clusterLBs[i].Transport)
-	}
-
-	clusters := make([]splitData, len(clusterLBs))
-
-	for i := 0; i < len(data); i++ {
-		clusterIndex := i % len(clusterLBs)
-		clusters[clusterIndex].Data = append(clusters[clusterIndex].Data, data[i])
-		clusters[clusterIndex].Weight = append(clusters[clusterIndex].Weight, weight[i])
-		clusters[clusterIndex].Transport = append(clusters[clusterIndex].Transport, transport[i])
-	}
-
-	backends := make([]splitWebServer, len(webServers))
-	for i := 0; i < len(session); i++ {
-		backendsIndex := i % len(webServers)
-		backends[backendsIndex].Session = append(backends[backendsIndex].Session, session[i])
-	}
-
-	response := Response {
-		TotalQueue: totalData,
-		CurrentQueue: currentQueue,
-		FirstReceivedQueue: firstReceivedQueue,
-		SecondReceivedQueue: secondReceivedQueue,
-		CurrentResponse: currentResponse,
-		CurrentTransport: totalTransport,
-		Data: data,
-		Weight: weight,
-		Transport: transport,
-		Session: session, 
-	}
-
-	file, err := os.Create(logFile)
-	if err != nil {
-		fmt.Println("failure creating csv file:", err)
-		return
-	}
-	defer file.Close()
-
-	var csvData strings.Builder
-	header := []string{"TotalQueue"}
-	header = append(header, "Queue")
-	header = append(header, "FirstReceivedQueue")
-	header = append(header, "SecondReceivedQueue")
-	header = append(header, "CurrentResponse")
-	header = append(header, "CurrentTransport")
-	for i := 0; i < len(clusterLBs); i++ {
-		header = append(header, fmt.Sprintf("%d_Data", clusterLBs[i].ID))
-	}
-	for i := 0; i < len(clusterLBs); i++ {
-		header = append(header, fmt.Sprintf("%d_Weight", clusterLBs[i].ID))
-	}
-	for i := 0; i < len(clusterLBs); i++ {
-		header = append(header, fmt.Sprintf("%d_Transport", clusterLBs[i].ID))
-	}
-	for i := 0; i < len(webServers); i++ {
-		header = append(header, fmt.Sprintf("%d_Session", webServers[i].ID))
-	}
-	
-	// Add here for CSV output if parameters increase
-	csvData.WriteString(strings.Join(header, ",") + "\n")
-
-	rowCount := len(response.CurrentQueue)
-
-	for i := 0; i < rowCount; i++ {
-		record := []string{fmt.Sprint(response.TotalQueue[i])}
-		record = append(record, strconv.Itoa(response.CurrentQueue[i]))
-		record = append(record, strconv.Itoa(response.FirstReceivedQueue[i]))
-		record = append(record, strconv.Itoa(response.SecondReceivedQueue[i]))
-		record = append(record, strconv.Itoa(response.CurrentResponse[i]))
-		record = append(record, strconv.Itoa(response.CurrentTransport[i]))
-		
-		for j := 0; j < len(clusterLBs); j++ {
-			if i < len(clusters[j].Data) {
-				record = append(record, strconv.Itoa(clusters[j].Data[i]))
-			} else {
-				record = append(record, "0") 
-			}
-		}
-		for j := 0; j < len(clusterLBs); j++ {
-			if i < len(clusters[j].Weight) {
-				record = append(record, strconv.Itoa(clusters[j].Weight[i]))
-			} else {
-				record = append(record, "0") 
-			}
-		}
-		for j := 0; j < len(clusterLBs); j++ {
-			if i < len(clusters[j].Transport) {
-				record = append(record, strconv.Itoa(clusters[j].Transport[i]))
-			} else {
-				record = append(record, "0") 
-			}
-		}
-		for j := 0; j < len(webServers); j++ {
-			if i < len(backends[j].Session) {
-				record = append(record, strconv.Itoa(backends[j].Session[i]))
-			} else {
-				record = append(record, "0") 
-			}
-		}
-
-		csvData.WriteString(strings.Join(record, ",") + "\n") 
-	}
-
-	_, err = file.WriteString(csvData.String())
-	if err != nil {
-		fmt.Println("Error writing to CSV file:", err)
-		return
-	}
-
-	w.Header().Set("Content-Type", "text/csv")
-	w.Header().Set("Content-Disposition", "attachment; filename="+logFile)
-
-	http.ServeFile(w, r, logFile)
-	
-	final = true
-} 
-
-// Function to convert a slice of weights into a comma-separated string
-func joinWeight(weight []int) string {
-	var result strings.Builder
-	for i, w := range weight {
-		if i > 0 {
-			result.WriteString(",")
-		}
-		result.WriteString(strconv.Itoa(w))
-	}
-	return result.String()
-}
-
-func getLastOctet(ip string) int {
-    parts := strings.Split(ip, ".")
-    if len(parts) != 4 {
-        fmt.Println("Invalid IP address format:", ip)
-        return -1
-    }
-    last, err := strconv.Atoi(parts[3])
-    if err != nil {
-        fmt.Println("Invalid IP address:", ip)
-        return -1
-    }
-    return last
-}
-
-func waitForAllLBsAndSyncStart(ctx context.Context, rdb *redis.Client, ownClusterLB string, totalLBs int, isLeader bool, redisKey string) {
-	pubsubChannel := "sync_start"
-
-	// Notify own readiness
-	if err := rdb.Set(ctx, redisKey+ownClusterLB, "true", 0).Err(); err != nil {
-		log.Fatalf("Redis SET failed: %v", err)
-	}
-	fmt.Println("LB Ready sent:", redisKey+ownClusterLB)
-
-	// Subscribe to sync_start (all LBs including the leader subscribe)
-	sub := rdb.Subscribe(ctx, pubsubChannel)
-	defer sub.Close()
-
-	// Prepare to receive the first message
-	_, err := sub.Receive(ctx)
-	if err != nil {
-		log.Fatalf("Failed to subscribe to %s: %v", pubsubChannel, err)
-	}
-	ch := sub.Channel()
-
-	if isLeader {
-		// Leader's role: publish sync_start when all are ready
-		fmt.Println("Coordinator waiting for all LB readiness...")
-		for {
-			keys, err := rdb.Keys(ctx, redisKey+"*").Result()
-			if err != nil {
-				log.Printf("Redis KEYS error: %v", err)
-				time.Sleep(1 * time.Second)
-				continue
-			}
-			if len(keys) == totalLBs {
-				fmt.Printf("%d/%d ready. Publishing sync_start...\n", len(keys), totalLBs)
-				break
-			}
-			fmt.Printf("%d/%d ready\n", len(keys), totalLBs)
-			time.Sleep(1 * time.Second)
-		}
-
-		// Send sync_start message
-		err := rdb.Publish(ctx, pubsubChannel, "start").Err()
-		if err != nil {
-			log.Fatalf("Failed to publish sync_start: %v", err)
-		}
-	}
-
-	// Wait for sync_start from all (including leader)
-	fmt.Println("Waiting for sync_start signal...")
-	for msg := range ch {
-		if msg.Payload == "start" {
-			fmt.Println("Received sync_start signal. Proceeding to main.")
-			break
-		}
-	}
-}
\ No newline at end of file
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"google.golang.org/grpc"
+
+	dcv1 "custome_weightedRR/api/dc/v1"
+	"custome_weightedRR/api/legacy"
+)
+
+// Unversioned API of the LB builds before dc.v1 (api/legacy), served while
+// old and new builds are mixed (legacy_api)
+type legacyServer struct {
+	legacy.UnimplementedLoadBalancerServer
+}
+
+// Health check to adjacent LBs
+func (s *legacyServer) GetBackendStatus(ctx context.Context, req *legacy.BackendRequest) (*legacy.BackendStatus, error) {
+	return &legacy.BackendStatus{IsHealthy: true}, nil
+}
+
+// Send control information to adjacent LBs
+func (s *legacyServer) ControlStream(stream legacy.LoadBalancer_ControlStreamServer) error {
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			log.Printf("Error receiving control message: %v", err)
+			return err
+		}
+
+		report := localReport("", true)
+		if err := stream.Send(&legacy.ControlResponse{Status: "ok", Payload: report.Queue}); err != nil {
+			log.Printf("Error sending response: %v", err)
+			return err
+		}
+	}
+}
+
+// Feedback with an adjacent LB that only speaks the legacy API.
+// Only the queue is exchanged; the other fields of the reports stay unset.
+type legacyStream struct {
+	stream legacy.LoadBalancer_ControlStreamClient
+}
+
+func (s legacyStream) Send(r *dcv1.LoadReport) error {
+	return s.stream.Send(&legacy.ControlMessage{Command: "update_policy", Payload: r.Queue})
+}
+
+func (s legacyStream) Recv() (*dcv1.LoadReport, error) {
+	in, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return &dcv1.LoadReport{Queue: in.Payload}, nil
+}
+
+func openLegacyFeedback(ctx context.Context, conn *grpc.ClientConn) (feedbackStream, string, error) {
+	client := legacy.NewLoadBalancerClient(conn)
+
+	hctx, cancel := context.WithTimeout(ctx, downTimeout())
+	res, err := client.GetBackendStatus(hctx, &legacy.BackendRequest{ServerName: "server-1"})
+	cancel()
+	if err != nil {
+		return nil, "", fmt.Errorf("health check failed: %v", err)
+	}
+	if !res.IsHealthy {
+		return nil, "", fmt.Errorf("not healthy")
+	}
+
+	stream, err := client.ControlStream(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating stream: %v", err)
+	}
+	return legacyStream{stream}, apiLegacy, nil
+}
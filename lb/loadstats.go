@@ -0,0 +1,171 @@
+package main
+
+import (
+	"time"
+
+	dcv1 "custome_weightedRR/api/dc/v1"
+)
+
+// Local load statistics advertised to the adjacent LBs in every report (guarded by mutex)
+var (
+	inFlight    int     // Requests forwarded to the own web servers without a response
+	arrivalRate float64 // Requests received per second (EWMA)
+	latency     float64 // Response time of the own web servers [ms] (EWMA)
+	serviceRate float64 // Responses per second while requests are pending (EWMA)
+	serverRate  float64 // Responses per second of one busy web server (EWMA)
+
+	lastArrivals  int
+	lastResponses int
+	lastRatesAt   time.Time
+
+	reportSeq   uint64                  // Sequence number of the last report sent
+	incarnation = time.Now().UnixNano() // The sequence restarts with every start of the LB
+)
+
+func ewma(avg, x float64) float64 {
+	return config.EWMAAlpha*x + (1-config.EWMAAlpha)*avg
+}
+
+// Update the arrival rate from totalQueue and the service rate from
+// responseCount (mutex held); called every sample. The service rate is only
+// measured while requests are pending, so that it keeps the last estimate
+// of what the web servers can process when the cluster is idle. The rate of
+// one web server divides it by the busy web servers, min(inFlight, servers).
+func updateRates(now time.Time) {
+	if !lastRatesAt.IsZero() {
+		if dt := now.Sub(lastRatesAt).Seconds(); dt > 0 {
+			arrivalRate = ewma(arrivalRate, float64(totalQueue-lastArrivals)/dt)
+			if inFlight > 0 {
+				rate := float64(responseCount-lastResponses) / dt
+				serviceRate = ewma(serviceRate, rate)
+				if busy := min(inFlight, len(webServers)); busy > 0 {
+					serverRate = ewma(serverRate, rate/float64(busy))
+				}
+			}
+		}
+	}
+	lastArrivals, lastResponses, lastRatesAt = totalQueue, responseCount, now
+}
+
+// Record the response time of an own web server (mutex held)
+func observeLatency(d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+	if latency == 0 {
+		latency = ms
+		return
+	}
+	latency = ewma(latency, ms)
+}
+
+// Record a round trip to clusterLBs[num] (mutex held): of the own report and
+// its answer on the feedback, or of a forwarded request without the time it
+// spent at the neighbor (handler.go)
+func observeRTT(num int, d time.Duration) {
+	lb := &clusterLBs[num]
+	if lb.RTT == 0 {
+		lb.RTT = d
+		return
+	}
+	lb.RTT = time.Duration(ewma(float64(lb.RTT), float64(d)))
+}
+
+// Capacity advertised to the adjacent LBs; defaults to the number of web servers
+func ownCapacity() float64 {
+	if config.Capacity > 0 {
+		return config.Capacity
+	}
+	return float64(len(webServers))
+}
+
+// Load report of this LB to the adjacent LB to ("" if unknown), sent every
+// feedback interval or as the answer to its report
+func localReport(to string, answer bool) *dcv1.LoadReport {
+	mutex.Lock()
+	defer mutex.Unlock()
+	reportSeq++
+	now := time.Now()
+	r := &dcv1.LoadReport{
+		Queue:       int64(queue),
+		NodeId:      ownNodeID,
+		Seq:         reportSeq,
+		Incarnation: incarnation,
+		SentAt:      now.UnixNano(),
+		Capacity:    ownCapacity(),
+		InFlight:    int64(inFlight),
+		ArrivalRate: arrivalRate,
+		Latency:     latency,
+		ServiceRate: serviceRate,
+		Degree:      int32(len(clusterLBs)),
+		Servers:     int32(len(webServers)),
+		ServerRate:  serverRate,
+	}
+	num := neighborIndex(to)
+	if num < 0 {
+		return r
+	}
+	if gossipEnabled() {
+		r.Gossip = gossipOut(num, now)
+	}
+	if n, ok := policy.(negotiator); ok {
+		n.annotate(num, r, answer)
+	}
+	return r
+}
+
+// Apply a report of the adjacent LB clusterLBs[num] (mutex held).
+// Reports whether it was applied; a report that is not newer than the last
+// one (reordered, or from a previous start of the neighbor) is dropped.
+// Reports of the legacy API have no sequence number and are always applied.
+func applyReport(num int, r *dcv1.LoadReport) bool {
+	lb := &clusterLBs[num]
+	if r.Seq != 0 {
+		if r.Incarnation < lb.Incarnation || (r.Incarnation == lb.Incarnation && r.Seq <= lb.Seq) {
+			lb.Reordered++
+			return false
+		}
+		lb.Incarnation, lb.Seq = r.Incarnation, r.Seq
+	}
+
+	now := time.Now()
+	lb.Data = int(r.Queue)
+	lb.Capacity = r.Capacity
+	lb.InFlight = int(r.InFlight)
+	lb.ArrivalRate = r.ArrivalRate
+	lb.Latency = r.Latency
+	lb.ServiceRate = r.ServiceRate
+	lb.Degree = int(r.Degree)
+	lb.Servers, lb.ServerRate = int(r.Servers), r.ServerRate
+	lb.Match, lb.MatchRound = r.Match, r.MatchRound
+	lb.Distance = int(r.Gradient) - 1
+	if r.Gossip != nil && gossipEnabled() {
+		gossipIn(num, r.Gossip, now)
+	}
+	lb.Updated = now
+	if _, ok := policy.(*centralControl); !ok {
+		lb.InTransit = 0 // The central budget is reset by SetWeights
+	}
+	updateEstimate(num, now)
+	if r.SentAt != 0 {
+		lb.Delay = now.Sub(time.Unix(0, r.SentAt))
+	}
+	return true
+}
+
+// Reports of a neighbor arrive in both directions of the feedback (its own
+// reports and its answers to ours), up to twice per feedback interval.
+// State that advances once per round (the momentum of sos, the integral and
+// derivative of pid, the slope of the trend estimator) therefore treats
+// reports less than half a feedback interval apart as the same round.
+func roundInterval() time.Duration {
+	return feedbackInterval() / 2
+}
+
+// Whether a report at now starts a new round after the one started at
+// *start (zero before the first round); if so, *start becomes now
+func newRound(start *time.Time, now time.Time) bool {
+	if !start.IsZero() && now.Sub(*start) < roundInterval() {
+		return false
+	}
+	*start = now
+	return true
+}
@@ -0,0 +1,375 @@
+// Load balancer between clusters with a forwarding policy selected at startup (-policy)
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	dcv1 "custome_weightedRR/api/dc/v1"
+	"custome_weightedRR/topology"
+)
+
+type LoadBalancer struct {
+	ID        string        // Node ID of the adjacent LB
+	Address   string        // host:port receiving forwarded requests
+	GRPC      string        // host:port of the feedback stream
+	IsHealthy bool          // Forward only to healthy neighbors
+	State     neighborState // Maintained by the feedback supervisor (health.go)
+	API       string        // API version of the feedback ("dc.v1" or "legacy")
+	Data      int           // Queue of the last report
+	Weight    int
+	Transport int
+	InTransit int     // Requests forwarded since the last report, not yet in its queue (estimator.go)
+	Kappa     float64 // Diffusion coefficient of the edge (edge.go)
+
+	Edge         topology.EdgeAttrs // Attributes of the edge in the adjacency list
+	RateTokens   float64            // Requests that may be forwarded now under max_rate
+	RateRefilled time.Time
+	Limited      int        // Requests turned away from the edge as it was over max_rate
+	Mode         modeSwitch // Forwarding mode of the threshold with hysteresis (edge.go)
+
+	Flow     float64 // Real-valued flow of the diffusion [requests per feedback interval] (flow, sos, pid, latency, central policy)
+	Tokens   float64 // Requests that may be forwarded now (flow, sos, pid, latency, dx policy)
+	LastFlow float64 // Signed flow of the current round (sos policy)
+	PrevFlow float64 // Signed flow of the previous round, the momentum (sos policy)
+	RoundAt  time.Time
+	PID      pidState // PID controller of the edge (pid policy)
+
+	Estimate loadEstimate // Estimate of the queue between the reports (estimator.go)
+
+	// Rest of the last report of the neighbor (loadstats.go)
+	Capacity    float64
+	InFlight    int
+	ArrivalRate float64
+	Latency     float64
+	ServiceRate float64
+	Degree      int
+	Servers     int        // Number of web servers (mmc policy)
+	ServerRate  float64    // Responses per second of one busy web server (mmc policy)
+	Match       dcv1.Match // Pairing request of the last report (dx policy)
+	MatchRound  uint64
+	Distance    int        // Hops from the neighbor to the nearest lightly loaded LB, -1 if not reported (gradient policy)
+	GossipMean  float64    // Estimate of the global mean queue of the neighbor (gossip.go)
+	GossipSent  gossipMass // Mass sent to the neighbor in this epoch
+	GossipRecv  gossipMass // Mass received from the neighbor in this epoch
+	Incarnation int64
+	Seq         uint64
+	Updated     time.Time     // Receive time of the last report
+	Delay       time.Duration // From sending to receiving the last report
+	RTT         time.Duration // Round trip of the feedback and the forwarded requests (EWMA)
+	Wait        float64       // Predicted wait of a request forwarded to the neighbor [ms] (mmc policy)
+	Reordered   int           // Reports dropped as not newer than the last one
+
+	StateChanges int // Number of state changes since the neighbor was added
+	Matches      int // Rounds of dimension exchange with the neighbor (dx policy)
+}
+
+// Values of an adjacent LB recorded every tick
+type neighborSample struct {
+	LoadBalancer
+	Age       time.Duration // Time since the last report, 0 before the first one
+	Estimated float64       // Estimated queue (estimator.go)
+}
+
+type webServer struct {
+	ID       string // Name in internalList ("web0")
+	Address  string // host:port
+	Weight   int
+	Sessions int
+}
+
+var (
+	clusterLBs []LoadBalancer
+	webServers []webServer
+
+	wg    sync.WaitGroup
+	mutex sync.RWMutex
+
+	ctx          = context.Background()
+	totalLBs     int
+	currentIndex int
+	ownNodeID    string // Node ID of this LB in the adjacency list
+	ownClusterLB string // host:port of this LB receiving requests
+	ownGRPC      string // host:port of this LB serving the feedback stream
+	isLeader     bool
+
+	// Adjacency list (json/adjacentList.json)
+	topo topology.Topology
+
+	// Resolved configuration (config.go)
+	config Config
+
+	// Forwarding policy selected with -policy
+	policy Policy
+
+	rdb *redis.Client
+
+	transportSet = &http.Transport{
+		MaxIdleConns:        1000,
+		MaxIdleConnsPerHost: 1000,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	// Evaluation parameters
+	queue              int // Number of pending TCP sessions
+	totalQueue         int // Total number of requests received by the LB
+	responseCount      int // Count of responses returned
+	currentTransport   int // Number of requests forwarded to adjacent LBs via reverse proxy
+	firstReceivedCount int // Number of requests directly received from adjacent LBs
+	adjacentQueueCount int // Number of multi-hop requests
+
+	totalData           []int
+	currentQueue        []int
+	firstReceivedQueue  []int
+	secondReceivedQueue []int
+	currentResponse     []int
+	totalTransport      []int
+	inFlightQueue       []int
+	arrivalRates        []float64
+	latencies           []float64
+	serviceRates        []float64
+	serverRates         []float64
+	waits               []float64
+	gossipMeans         []float64
+	gossipCounts        []float64
+	gossipCountErrors   []float64
+	gossipSpreads       []float64
+
+	// Feedback information obtained from adjacent LBs, one map per tick keyed by node ID
+	neighborHistory []map[string]neighborSample
+	neighborIDs     []string // every adjacent LB recorded so far
+	seenNeighbors   = make(map[string]bool)
+	session         []int
+
+	final bool
+)
+
+// Resolve the configuration, the topology and the own node before serving
+func setup() {
+	var printConfig bool
+	var err error
+	config, printConfig, err = loadConfig(os.Args[1:])
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	err = config.Validate()
+	if printConfig {
+		fmt.Println(config)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	policy, err = newPolicy(config.Policy)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Cluster Number: %d\n", config.Cluster)
+	fmt.Printf("policy -policy : %s\n", config.Policy)
+	fmt.Printf("feedback -t : %d\n", config.Feedback)
+	fmt.Printf("threshold -q : %d\n", config.Threshold)
+	fmt.Printf("kappa -k : %.2f\n", config.Kappa)
+
+	topo, err = topology.Load(config.AdjacencyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	totalLBs = len(topo)
+
+	ports := topologyPorts()
+	ownNodeID = config.NodeID
+	if ownNodeID == "" {
+		ownNodeID, err = detectNodeID(topo, ports)
+		if err != nil {
+			log.Fatalf("Failed to identify own node (set -node-id): %v", err)
+		}
+		fmt.Println("Node ID detected from local addresses:", ownNodeID)
+	}
+
+	// Refuse to start on a broken topology instead of failing during the load test
+	report := topology.Check(topo, ports, ownNodeID, config.Leader)
+	fmt.Print(report)
+	if err := report.Err(); err != nil {
+		log.Fatalf("%s: %v", config.AdjacencyFile, err)
+	}
+
+	ownClusterLB, err = topo.HTTPAddr(ownNodeID, ports)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ownGRPC, err = topo.GRPCAddr(ownNodeID, ports)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	neighbors, err := topo.Neighbors(ownNodeID, ports)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, n := range neighbors {
+		clusterLBs = append(clusterLBs, LoadBalancer{
+			ID:        n.ID,
+			Address:   n.HTTP,
+			GRPC:      n.GRPC,
+			IsHealthy: false,
+			State:     stateDown,
+			Data:      0,
+			Weight:    0,
+			Transport: 0,
+			Edge:      n.EdgeAttrs,
+			Distance:  -1,
+		})
+	}
+	for i := range clusterLBs {
+		exportEdgeKappa(i)
+	}
+
+	backends, err := topo.Backends(ownNodeID, ports)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, b := range backends {
+		webServers = append(webServers, webServer{
+			ID:       b.ID,
+			Address:  b.Addr,
+			Weight:   0,
+			Sessions: 0,
+		})
+	}
+
+	fmt.Println(ownNodeID, ownClusterLB, ownGRPC, config.Leader)
+	fmt.Println(clusterLBs, webServers)
+
+	registerMetrics()
+
+	// redis client
+	rdb = redis.NewClient(&redis.Options{
+		Addr: config.RedisAddr,
+		DB:   0,
+	})
+
+	isLeader = ownNodeID == config.Leader // Only the leader LB is set to true
+	waitForAllLBsAndSyncStart(ctx, rdb, ownNodeID, totalLBs, isLeader, "lb_ready:")
+}
+
+func main() {
+	setup()
+
+	wg.Add(1)
+	go gRPC_Server()
+
+	mutex.Lock()
+	for _, lb := range clusterLBs {
+		startFeedback(lb)
+	}
+	started = true
+	mutex.Unlock()
+
+	if config.WatchInterval > 0 {
+		go watchTopology()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s := http.Server{
+			Addr:    listenOn(ownClusterLB),
+			Handler: http.HandlerFunc(lbHandler),
+		}
+
+		fmt.Printf("HTTP server is listening on %s...\n", s.Addr)
+		if err := s.ListenAndServe(); err != nil {
+			log.Fatal(err.Error())
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s := http.Server{
+			Addr:    listenAddr(config.DataPort),
+			Handler: dataMux(),
+		}
+
+		fmt.Printf("HTTP server is listening on %s...\n", s.Addr)
+		if err := s.ListenAndServe(); err != nil {
+			log.Fatal(err.Error())
+		}
+	}()
+
+	go metricsExporter()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			if final {
+				os.Exit(1)
+			}
+
+			mutex.Lock()
+			now := time.Now()
+			updateRates(now)
+			totalData = append(totalData, totalQueue)
+			currentQueue = append(currentQueue, queue)
+			firstReceivedQueue = append(firstReceivedQueue, firstReceivedCount)
+			secondReceivedQueue = append(secondReceivedQueue, adjacentQueueCount)
+			currentResponse = append(currentResponse, responseCount)
+			totalTransport = append(totalTransport, currentTransport)
+			inFlightQueue = append(inFlightQueue, inFlight)
+			arrivalRates = append(arrivalRates, arrivalRate)
+			latencies = append(latencies, latency)
+			serviceRates = append(serviceRates, serviceRate)
+			serverRates = append(serverRates, serverRate)
+			wait, _ := mmcWait(queue, len(webServers), serverRate)
+			waits = append(waits, wait)
+			mean, count := gossipEstimate(now)
+			gossipMeans = append(gossipMeans, mean)
+			gossipCounts = append(gossipCounts, count)
+			countError := 0.0 // Nothing is estimated without gossip
+			if gossipEnabled() {
+				countError = count - float64(len(topo))
+			}
+			gossipCountErrors = append(gossipCountErrors, countError)
+			gossipSpreads = append(gossipSpreads, gossipSpread(mean))
+
+			samples := make(map[string]neighborSample, len(clusterLBs))
+			for i, server := range clusterLBs {
+				sample := neighborSample{LoadBalancer: server, Estimated: estimateLoad(i, now)}
+				if !server.Updated.IsZero() {
+					sample.Age = now.Sub(server.Updated)
+				}
+				samples[server.ID] = sample
+				if !seenNeighbors[server.ID] {
+					seenNeighbors[server.ID] = true
+					neighborIDs = append(neighborIDs, server.ID)
+				}
+			}
+			neighborHistory = append(neighborHistory, samples)
+			for _, backend := range webServers {
+				session = append(session, backend.Sessions)
+			}
+			mutex.Unlock()
+
+			time.Sleep(time.Duration(config.SampleInterval) * time.Millisecond) // ms
+		}
+	}()
+
+	wg.Wait()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	activeSessions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "active_sessions",
+			Help: "Current number of active sessions",
+		},
+		[]string{"cluster", "instance"},
+	)
+	totalRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "total_requests",
+			Help: "Total number of requests",
+		},
+		[]string{"cluster", "instance"},
+	)
+	neighborStates = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "neighbor_state",
+			Help: "State of the adjacent LB (2: healthy, 1: suspect, 0: down)",
+		},
+		[]string{"cluster", "neighbor"},
+	)
+	edgeKappas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "edge_kappa",
+			Help: "Diffusion coefficient of the edge to the adjacent LB",
+		},
+		[]string{"cluster", "neighbor"},
+	)
+	pidTerms = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pid_term",
+			Help: "Term of the PID controller of the edge to the adjacent LB (p, i, d) [requests per feedback interval]",
+		},
+		[]string{"cluster", "neighbor", "term"},
+	)
+	modeChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "forwarding_mode_changes_total",
+			Help: "Number of times forwarding over the edge to the adjacent LB was switched on or off by the threshold",
+		},
+		[]string{"cluster", "neighbor"},
+	)
+	neighborStateChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "neighbor_state_changes_total",
+			Help: "Number of state changes of the adjacent LB, by new state",
+		},
+		[]string{"cluster", "neighbor", "state"},
+	)
+)
+
+// Register exporter
+func registerMetrics() {
+	prometheus.MustRegister(activeSessions)
+	prometheus.MustRegister(totalRequests)
+	prometheus.MustRegister(neighborStates)
+	prometheus.MustRegister(neighborStateChanges)
+	prometheus.MustRegister(edgeKappas)
+	prometheus.MustRegister(pidTerms)
+	prometheus.MustRegister(modeChanges)
+}
+
+// Expose the metrics for the Prometheus federation (prometheus/federation/prometheus.yml)
+func metricsExporter() {
+	exporterMux := http.NewServeMux()
+	exporterMux.Handle("/federate", promhttp.Handler())
+	fmt.Printf("Exporter listening on %s\n", listenAddr(config.MetricsPort))
+	if err := http.ListenAndServe(listenAddr(config.MetricsPort), exporterMux); err != nil {
+		fmt.Printf("Exporter server error: %v\n", err)
+	}
+}
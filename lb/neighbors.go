@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"custome_weightedRR/topology"
+)
+
+var (
+	// Cancel functions of the running feedback goroutines, keyed by neighbor node ID (guarded by mutex)
+	feedbackStops = make(map[string]context.CancelFunc)
+	// Transport counts of removed neighbors, restored when they are added again
+	removedTransport = make(map[string]int)
+	// Set once main starts the feedback goroutines (guarded by mutex)
+	started bool
+)
+
+// Index of the adjacent LB in clusterLBs, or -1 (mutex held)
+func neighborIndex(id string) int {
+	for i, lb := range clusterLBs {
+		if lb.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Start the feedback goroutine of an adjacent LB (mutex held)
+func startFeedback(lb LoadBalancer) {
+	fctx, cancel := context.WithCancel(ctx)
+	feedbackStops[lb.ID] = cancel
+
+	wg.Add(1)
+	go gRPC_Client(fctx, lb.ID, lb.GRPC)
+}
+
+// Add an adjacent LB and start its feedback; an existing neighbor whose
+// addresses changed is restarted. Reports whether anything changed (mutex held).
+func addNeighbor(n topology.Neighbor) bool {
+	if i := neighborIndex(n.ID); i >= 0 {
+		if clusterLBs[i].Address == n.HTTP && clusterLBs[i].GRPC == n.GRPC {
+			if clusterLBs[i].Edge.Equal(n.EdgeAttrs) {
+				return false
+			}
+			clusterLBs[i].Edge = n.EdgeAttrs
+			exportEdgeKappa(i)
+			log.Printf("Neighbor %s: edge attributes changed", n.ID)
+			return true
+		}
+		removeNeighbor(n.ID)
+	}
+
+	lb := LoadBalancer{
+		ID:        n.ID,
+		Address:   n.HTTP,
+		GRPC:      n.GRPC,
+		IsHealthy: false,
+		State:     stateDown,
+		Data:      0,
+		Weight:    0,
+		Transport: removedTransport[n.ID],
+		Edge:      n.EdgeAttrs,
+		Distance:  -1,
+	}
+	delete(removedTransport, n.ID)
+	clusterLBs = append(clusterLBs, lb)
+	sortNeighbors()
+	exportEdgeKappa(neighborIndex(n.ID))
+
+	if started {
+		startFeedback(lb)
+	}
+	log.Printf("Neighbor %s added (%s, gRPC %s)", n.ID, n.HTTP, n.GRPC)
+	return true
+}
+
+// Stop the feedback of an adjacent LB and stop forwarding to it (mutex held)
+func removeNeighbor(id string) bool {
+	i := neighborIndex(id)
+	if i < 0 {
+		return false
+	}
+	if stop, ok := feedbackStops[id]; ok {
+		stop()
+		delete(feedbackStops, id)
+	}
+	removedTransport[id] = clusterLBs[i].Transport
+	neighborStates.DeleteLabelValues(ownNodeID, id)
+	edgeKappas.DeleteLabelValues(ownNodeID, id)
+	modeChanges.DeleteLabelValues(ownNodeID, id)
+	labels := prometheus.Labels{"cluster": ownNodeID, "neighbor": id}
+	pidTerms.DeletePartialMatch(labels)
+	neighborStateChanges.DeletePartialMatch(labels)
+	clusterLBs = append(clusterLBs[:i], clusterLBs[i+1:]...)
+
+	log.Printf("Neighbor %s removed", id)
+	return true
+}
+
+// Make the adjacent LBs match the neighbors listed for this node in t (mutex held)
+func applyTopology(t topology.Topology) error {
+	neighbors, err := t.Neighbors(ownNodeID, topologyPorts())
+	if err != nil {
+		return err
+	}
+
+	desired := make(map[string]bool)
+	for _, n := range neighbors {
+		desired[n.ID] = true
+		addNeighbor(n)
+	}
+	for _, lb := range append([]LoadBalancer(nil), clusterLBs...) {
+		if !desired[lb.ID] {
+			removeNeighbor(lb.ID)
+		}
+	}
+	topo = t
+	return nil
+}
+
+func sortNeighbors() {
+	for i := 1; i < len(clusterLBs); i++ {
+		for j := i; j > 0 && topology.LessID(clusterLBs[j].ID, clusterLBs[j-1].ID); j-- {
+			clusterLBs[j], clusterLBs[j-1] = clusterLBs[j-1], clusterLBs[j]
+		}
+	}
+}
+
+// Poll the adjacency list and apply changes of the own neighbors.
+// A topology with errors is reported and ignored.
+func watchTopology() {
+	interval := time.Duration(config.WatchInterval) * time.Millisecond
+	var lastMod time.Time
+	var lastSize int64
+	if info, err := os.Stat(config.AdjacencyFile); err == nil {
+		lastMod, lastSize = info.ModTime(), info.Size()
+	}
+
+	for range time.Tick(interval) {
+		info, err := os.Stat(config.AdjacencyFile)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Equal(lastMod) && info.Size() == lastSize {
+			continue
+		}
+		lastMod, lastSize = info.ModTime(), info.Size()
+
+		t, err := topology.Load(config.AdjacencyFile)
+		if err != nil {
+			log.Printf("Ignoring adjacency list change: %v", err)
+			continue
+		}
+		report := topology.Check(t, topologyPorts(), ownNodeID, config.Leader)
+		if err := report.Err(); err != nil {
+			log.Printf("Ignoring adjacency list change: %v", err)
+			continue
+		}
+
+		mutex.Lock()
+		err = applyTopology(t)
+		mutex.Unlock()
+		if err != nil {
+			log.Printf("Failed to apply adjacency list change: %v", err)
+			continue
+		}
+		log.Printf("Adjacency list reloaded from %s", config.AdjacencyFile)
+	}
+}
+
+// Admin endpoint for the adjacent LBs
+//
+//	GET    /neighbors          list the adjacent LBs
+//	POST   /neighbors          add {"id": "cluster3", "address": "172.18.4.5:8001", "grpc": "172.18.4.5:50051"}
+//	                           with optional edge attributes ("kappa", "threshold", "cost", "max_rate")
+//	DELETE /neighbors?id=<id>  remove an adjacent LB
+//
+// Changes made here are replaced by the next change of the adjacency list file.
+func neighborsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		mutex.RLock()
+		list := append([]LoadBalancer(nil), clusterLBs...)
+		mutex.RUnlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodPost:
+		var req struct {
+			ID      string `json:"id"`
+			Address string `json:"address"`
+			GRPC    string `json:"grpc"`
+			topology.EdgeAttrs
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		n, err := resolveNeighbor(req.ID, req.Address, req.GRPC)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := req.EdgeAttrs.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		n.EdgeAttrs = req.EdgeAttrs
+
+		mutex.Lock()
+		added := addNeighbor(n)
+		mutex.Unlock()
+		if added {
+			w.WriteHeader(http.StatusCreated)
+		}
+		fmt.Fprintf(w, "%s: %s (gRPC %s)\n", n.ID, n.HTTP, n.GRPC)
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		mutex.Lock()
+		removed := removeNeighbor(id)
+		mutex.Unlock()
+		if !removed {
+			http.Error(w, fmt.Sprintf("neighbor %q not found", id), http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, "%s removed\n", id)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Fill the default ports of a neighbor given through the admin endpoint
+func resolveNeighbor(id, address, grpcAddr string) (topology.Neighbor, error) {
+	ports := topologyPorts()
+	if id == "" || id == ownNodeID {
+		return topology.Neighbor{}, fmt.Errorf("invalid neighbor id %q", id)
+	}
+	httpAddr, err := topology.WithDefaultPort(address, ports.HTTP)
+	if err != nil {
+		return topology.Neighbor{}, fmt.Errorf("address: %v", err)
+	}
+	if grpcAddr == "" {
+		host, _, _ := net.SplitHostPort(httpAddr)
+		grpcAddr = net.JoinHostPort(host, strconv.Itoa(ports.GRPC))
+	}
+	grpcAddr, err = topology.WithDefaultPort(grpcAddr, ports.GRPC)
+	if err != nil {
+		return topology.Neighbor{}, fmt.Errorf("grpc: %v", err)
+	}
+	if grpcAddr == httpAddr {
+		return topology.Neighbor{}, fmt.Errorf("grpc must differ from address %s", httpAddr)
+	}
+	return topology.Neighbor{ID: id, HTTP: httpAddr, GRPC: grpcAddr}, nil
+}
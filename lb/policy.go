@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	dcv1 "custome_weightedRR/api/dc/v1"
+)
+
+// Policy decides how requests are distributed between clusters.
+// All methods are called with mutex held, so implementations may read and
+// update clusterLBs and the evaluation parameters directly.
+type Policy interface {
+	// Forward reports whether the current request should leave the cluster
+	Forward() bool
+	// Select returns the index of the adjacent LB in clusterLBs to forward to,
+	// or -1 to process the request with the internal web servers. The
+	// adjacent LBs marked in skip (nil, or indexed like clusterLBs) must not
+	// be returned; they are the edges already found over their max_rate for
+	// the current request (edge.go)
+	Select(skip []bool) int
+	// Calculate is called each time feedback information (next_queue) from
+	// the adjacent LB clusterLBs[num] is obtained; the rest of its report
+	// (capacity, arrival rate, ...) is already stored in clusterLBs[num]
+	Calculate(next_queue int, num int)
+}
+
+// Whether clusterLBs[i] may be selected for the current request: healthy and
+// not in skip (see Policy.Select)
+func candidate(i int, skip []bool) bool {
+	return clusterLBs[i].IsHealthy && (skip == nil || !skip[i])
+}
+
+// Policies that negotiate with the adjacent LBs through the feedback reports
+type negotiator interface {
+	// annotate fills the fields of the report r to the adjacent LB
+	// clusterLBs[num], sent on the own stream every feedback interval or,
+	// with answer, as the answer to the report of the neighbor
+	annotate(num int, r *dcv1.LoadReport, answer bool)
+}
+
+// Policies that route requests forwarded by an adjacent LB on to another one
+type transitRouter interface {
+	// Transit replaces Forward for a request forwarded hops times so far,
+	// the last time by the adjacent LB from; Select then chooses the next hop
+	Transit(from string, hops int) bool
+}
+
+// Policies that spend a token of the adjacent LB in Select
+type tokenSpender interface {
+	// refund gives back the token spent on clusterLBs[num] when the request
+	// is not forwarded there after all (the edge is over its max_rate)
+	refund(num int)
+}
+
+// Constructors of the available policies, keyed by the -policy flag value
+var policies = map[string]func() Policy{
+	"threshold": func() Policy { return &thresholdDC{} },
+	"diff":      func() Policy { return &diffDC{} },
+	"flow":      func() Policy { return &flowDC{} },
+	"sos":       func() Policy { return &sosDC{} },
+	"dx":        func() Policy { return &dimensionExchange{} },
+	"gradient":  func() Policy { return &gradientModel{} },
+	"mean":      func() Policy { return &aboveMean{} },
+	"pid":       func() Policy { return &pidDC{} },
+	"mmc":       func() Policy { return &queueingModel{} },
+	"latency":   func() Policy { return &latencyDC{} },
+	"central":   func() Policy { return &centralControl{} },
+	"rr":        func() Policy { return &roundRobin{} },
+	"lc":        func() Policy { return &leastConn{} },
+	"random":    func() Policy { return &randomNeighbor{} },
+	"static":    func() Policy { return &staticCapacity{} },
+	"jsq":       func() Policy { return &joinShortestQueue{} },
+	"local":     func() Policy { return &localOnly{} },
+}
+
+func newPolicy(name string) (Policy, error) {
+	constructor, ok := policies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown policy %q (available: %v)", name, policyNames())
+	}
+	return constructor(), nil
+}
+
+func policyNames() []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
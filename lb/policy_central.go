@@ -0,0 +1,55 @@
+package main
+
+import (
+	"time"
+
+	dcv1 "custome_weightedRR/api/dc/v1"
+)
+
+// Comparison baseline with a global view: the weights of the adjacent LBs are
+// pushed by the central controller (controller/, SetWeights) instead of being
+// computed from the feedback. Requests are forwarded with the weighted round
+// robin while some healthy neighbor has a positive weight. Weights that the
+// controller has not renewed within the down timeout are ignored, so that the
+// LBs stop forwarding when the controller fails.
+type centralControl struct {
+	updated time.Time // Last SetWeights
+	round   uint64    // Round of the controller of the last SetWeights
+}
+
+func (p *centralControl) Forward() bool {
+	if time.Since(p.updated) > downTimeout() {
+		return false
+	}
+	for i, lb := range clusterLBs {
+		if lb.IsHealthy && lb.Weight > 0 && withinBudget(i) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *centralControl) Select(skip []bool) int {
+	return WeightedRoundRobin_AdjacentLB(skip, withinBudget)
+}
+
+// The weights are set by the controller only
+func (p *centralControl) Calculate(next_queue int, num int) {}
+
+// Replace the weights of all adjacent LBs; neighbors without an entry get
+// weight 0 and entries of unknown neighbors are ignored
+func (p *centralControl) setWeights(req *dcv1.SetWeightsRequest) {
+	weights := make(map[string]*dcv1.NeighborWeight, len(req.Weights))
+	for _, w := range req.Weights {
+		weights[w.NodeId] = w
+	}
+	for i := range clusterLBs {
+		lb := &clusterLBs[i]
+		lb.Weight, lb.Flow = 0, 0
+		lb.InTransit = 0
+		if w, ok := weights[lb.ID]; ok && w.Weight > 0 {
+			lb.Weight, lb.Flow = int(w.Weight), w.Flow
+		}
+	}
+	p.updated, p.round = time.Now(), req.Round
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// DC method based on threshold to specify the destination
+// Forward when the own queue exceeds the threshold, to the adjacent LBs
+// whose edge threshold is exceeded and whose weight is not used up
+type thresholdDC struct{}
+
+func (p *thresholdDC) Forward() bool {
+	return anyOverThreshold()
+}
+
+func (p *thresholdDC) Select(skip []bool) int {
+	return WeightedRoundRobin_AdjacentLB(skip, func(i int) bool {
+		return overThreshold(i) && withinBudget(i)
+	})
+}
+
+func (p *thresholdDC) Calculate(next_queue int, num int) {
+	Calculate(next_queue, num)
+}
+
+// DC method based on session difference between adjacent LBs
+// Forward when the difference to an adjacent LB exceeds the threshold,
+// or, when the threshold is 0, whenever some weight is positive
+type diffDC struct{}
+
+func (p *diffDC) Forward() bool {
+	for i, info := range clusterLBs {
+		// When the threshold is 0 or more
+		if threshold := edgeThreshold(i); threshold > 0 {
+			if overDiff(i, threshold) {
+				return true
+			}
+		} else if info.Weight > 0 && withinBudget(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// Adjacent LBs whose edge has its own threshold are only selected while the
+// difference exceeds it, and only while their weight is not used up
+func (p *diffDC) Select(skip []bool) int {
+	return WeightedRoundRobin_AdjacentLB(skip, func(i int) bool {
+		t := clusterLBs[i].Edge.Threshold
+		return withinBudget(i) && (t == nil || overDiff(i, *t))
+	})
+}
+
+// Whether the load difference to clusterLBs[num] exceeds threshold (with
+// the hysteresis of the edge)
+func overDiff(num int, threshold int) bool {
+	return clusterLBs[num].Mode.update(loadDiff(num, neighborLoad(num)), threshold, time.Now(), ownNodeID, clusterLBs[num].ID)
+}
+
+func (p *diffDC) Calculate(next_queue int, num int) {
+	Calculate(next_queue, num)
+}
+
+// Weighted Round Robin between clusters (distribution to adjacent LBs)
+// Only candidates (not in skip) accepted by eligible (nil accepts all) are selected.
+// Returns -1 when no such adjacent LB has a positive weight
+func WeightedRoundRobin_AdjacentLB(skip []bool, eligible func(i int) bool) int {
+	// eligible may update state (the hysteresis of the edges), so it is
+	// evaluated once per request and both loops below use the result
+	selectable := make([]bool, len(clusterLBs))
+	for i := range clusterLBs {
+		selectable[i] = candidate(i, skip) && (eligible == nil || eligible(i))
+	}
+
+	// Weights are dynamically obtained
+	totalWeight := 0
+	for i, server := range clusterLBs {
+		if selectable[i] {
+			totalWeight += server.Weight
+		}
+	}
+
+	// If all weights are 0 (when no adjacent LBs are available)
+	if totalWeight == 0 {
+		return -1
+	}
+
+	// Generate a random number from 0 to totalWeight-1
+	randomWeight := rand.Intn(totalWeight)
+
+	// Select server based on weight
+	for i, server := range clusterLBs {
+		if !selectable[i] {
+			continue
+		}
+		if randomWeight < server.Weight {
+			return i
+		}
+		randomWeight -= server.Weight
+	}
+
+	return -1
+}
+
+// Call this function each time feedback information from adjacent LBs is obtained
+// Calculate the number of requests to be forwarded (weight)
+// The cost of the edge divides the weight, so that expensive links carry less
+func Calculate(next_queue int, num int) {
+	// Calculate using DC method
+	kappa := edgeKappa(num)
+	if diff := loadDiff(num, next_queue); diff > 0 {
+		clusterLBs[num].Weight = int(math.Round(kappa * diff / clusterLBs[num].Edge.CostOrDefault()))
+	} else {
+		clusterLBs[num].Weight = 0
+	}
+}
+
+// Load difference to the adjacent LB clusterLBs[num] in requests.
+// With normalize, the difference of the load per unit of capacity, scaled
+// back to requests with the own capacity, so that clusters converge to the
+// same load relative to what they can process.
+func loadDiff(num int, next_queue int) float64 {
+	own, next, ok := capacities(num)
+	if !ok {
+		return float64(queue - next_queue)
+	}
+	return (float64(queue)/own - float64(next_queue)/next) * own
+}
+
+// Own and neighbor capacity used by normalize. ok is false without
+// normalization or when the neighbor reports no capacity (legacy API).
+// The measured service rates are used once both sides have one.
+func capacities(num int) (own, next float64, ok bool) {
+	switch config.Normalize {
+	case normalizeRate:
+		if serviceRate > 0 && clusterLBs[num].ServiceRate > 0 {
+			return serviceRate, clusterLBs[num].ServiceRate, true
+		}
+		fallthrough
+	case normalizeCapacity:
+		own, next = ownCapacity(), clusterLBs[num].Capacity
+		return own, next, own > 0 && next > 0
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+
+	dcv1 "custome_weightedRR/api/dc/v1"
+)
+
+// Dimension exchange: instead of diffusing to all adjacent LBs at once,
+// each round an LB pairs with one neighbor and the two balance pairwise.
+// The pairs form a randomized matching negotiated in the feedback reports:
+//
+//   - An unpaired LB picks a random healthy neighbor with probability 1/2 each
+//     round and proposes on its own stream (MATCH_PROPOSE with a new round).
+//   - A neighbor accepts in the answer to that report (MATCH_ACCEPT echoing
+//     the round) unless it is paired or waits for the answer to its own
+//     proposal to another LB.
+//   - A pair lasts one feedback interval. The more loaded side forwards the
+//     half of the difference (the share that equalizes the load per capacity
+//     with normalize), then both are free again.
+type dimensionExchange struct {
+	target  string    // Neighbor to propose to in this round, "" to wait for proposals
+	round   uint64    // Round of the proposal sent to target, 0 before sending
+	rounds  uint64    // Proposals sent so far
+	decided time.Time // Start of the current round
+
+	partner  string    // Paired neighbor
+	until    time.Time // End of the pairing
+	accepted uint64    // Round of the proposal of partner to accept in the next answer
+}
+
+func feedbackInterval() time.Duration {
+	return time.Duration(config.Feedback) * time.Millisecond
+}
+
+func (p *dimensionExchange) Forward() bool {
+	return p.next(time.Now(), nil) >= 0
+}
+
+func (p *dimensionExchange) Select(skip []bool) int {
+	next := p.next(time.Now(), skip)
+	if next >= 0 {
+		clusterLBs[next].Tokens--
+	}
+	return next
+}
+
+func (p *dimensionExchange) refund(num int) {
+	clusterLBs[num].Tokens++
+}
+
+// Paired neighbor if it is a candidate and a whole request of the exchange
+// is left, or -1
+func (p *dimensionExchange) next(now time.Time, skip []bool) int {
+	p.expire(now)
+	num := neighborIndex(p.partner)
+	if num < 0 || !candidate(num, skip) || clusterLBs[num].Tokens < 1 {
+		return -1
+	}
+	return num
+}
+
+func (p *dimensionExchange) Calculate(next_queue int, num int) {
+	now := time.Now()
+	p.expire(now)
+	lb := &clusterLBs[num]
+	switch lb.Match {
+	case dcv1.Match_MATCH_PROPOSE:
+		// Free, and not waiting for the answer of another neighbor
+		if p.partner == "" && (p.round == 0 || p.target == lb.ID) {
+			p.pair(num, now)
+			p.accepted = lb.MatchRound
+		}
+	case dcv1.Match_MATCH_ACCEPT:
+		if p.partner == "" && p.target == lb.ID && p.round == lb.MatchRound {
+			p.pair(num, now)
+		}
+	}
+}
+
+func (p *dimensionExchange) annotate(num int, r *dcv1.LoadReport, answer bool) {
+	now := time.Now()
+	p.expire(now)
+	id := clusterLBs[num].ID
+	if answer {
+		if p.partner == id && p.accepted != 0 {
+			r.Match, r.MatchRound = dcv1.Match_MATCH_ACCEPT, p.accepted
+			p.accepted = 0
+		}
+		return
+	}
+
+	if p.partner != "" {
+		return
+	}
+	if now.Sub(p.decided) >= feedbackInterval() {
+		p.decide(now)
+	}
+	if p.target == id && p.round == 0 && clusterLBs[num].IsHealthy {
+		p.rounds++
+		p.round = p.rounds
+		r.Match, r.MatchRound = dcv1.Match_MATCH_PROPOSE, p.round
+	}
+}
+
+// Start a round: propose to a random healthy neighbor, or wait for proposals
+func (p *dimensionExchange) decide(now time.Time) {
+	p.target, p.round, p.decided = "", 0, now
+	var healthy []string
+	for _, lb := range clusterLBs {
+		if lb.IsHealthy {
+			healthy = append(healthy, lb.ID)
+		}
+	}
+	if len(healthy) > 0 && rand.Intn(2) == 0 {
+		p.target = healthy[rand.Intn(len(healthy))]
+	}
+}
+
+// Pair with clusterLBs[num] for one interval and take the own share of the exchange
+func (p *dimensionExchange) pair(num int, now time.Time) {
+	lb := &clusterLBs[num]
+	p.partner, p.until = lb.ID, now.Add(feedbackInterval())
+	p.target, p.round = "", 0
+	lb.Matches++
+	lb.Tokens = max(exchange(num), 0)
+}
+
+func (p *dimensionExchange) expire(now time.Time) {
+	if p.partner == "" || now.Before(p.until) {
+		return
+	}
+	if num := neighborIndex(p.partner); num >= 0 {
+		clusterLBs[num].Tokens = 0
+	}
+	p.partner, p.accepted = "", 0
+	p.decided = time.Time{} // A new round starts right away
+}
+
+// Requests to forward to clusterLBs[num] so that both sides have the same
+// load, or the same load per capacity with normalize
+func exchange(num int) float64 {
+	diff := loadDiff(num, neighborLoad(num))
+	if own, next, ok := capacities(num); ok {
+		return diff * next / (own + next)
+	}
+	return diff / 2
+}
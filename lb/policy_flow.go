@@ -0,0 +1,79 @@
+package main
+
+import "time"
+
+// Continuous DC: the diffusion equation gives a real-valued flow to each
+// adjacent LB, kappa * (own load - neighbor load) requests per feedback
+// interval (kappa and cost of the edge, edge.go), instead of a rounded weight.
+// Each neighbor earns fractional tokens at the rate of its flow, and a
+// request is forwarded when a neighbor has a whole token, so that flows
+// below one request per interval are forwarded too.
+type flowDC struct {
+	refilled time.Time
+}
+
+func (p *flowDC) Forward() bool {
+	p.refill(time.Now())
+	return p.next(nil) >= 0
+}
+
+func (p *flowDC) Select(skip []bool) int {
+	next := p.next(skip)
+	if next >= 0 {
+		clusterLBs[next].Tokens--
+	}
+	return next
+}
+
+func (p *flowDC) refund(num int) {
+	clusterLBs[num].Tokens++
+}
+
+func (p *flowDC) Calculate(next_queue int, num int) {
+	p.refill(time.Now())
+	setFlow(num, edgeKappa(num)*loadDiff(num, next_queue)/clusterLBs[num].Edge.CostOrDefault())
+}
+
+// Set the flow to clusterLBs[num]. A flow of 0 or less stops forwarding to
+// the neighbor, and the tokens left are dropped.
+func setFlow(num int, flow float64) {
+	if flow <= 0 {
+		clusterLBs[num].Flow = 0
+		clusterLBs[num].Tokens = 0
+		return
+	}
+	clusterLBs[num].Flow = flow
+}
+
+// Add the tokens earned since the last refill. Up to one interval of flow,
+// or one token for flows below one request, is kept while no request arrives.
+func (p *flowDC) refill(now time.Time) {
+	if !p.refilled.IsZero() {
+		intervals := float64(now.Sub(p.refilled)) / float64(time.Duration(config.Feedback)*time.Millisecond)
+		for i := range clusterLBs {
+			lb := &clusterLBs[i]
+			if !lb.IsHealthy || lb.Flow <= 0 {
+				continue
+			}
+			lb.Tokens += lb.Flow * intervals
+			if limit := max(lb.Flow, 1); lb.Tokens > limit {
+				lb.Tokens = limit
+			}
+		}
+	}
+	p.refilled = now
+}
+
+// Candidate adjacent LB with the most tokens, if it has a whole one, or -1
+func (p *flowDC) next(skip []bool) int {
+	next := -1
+	for i, lb := range clusterLBs {
+		if !candidate(i, skip) || lb.Tokens < 1 {
+			continue
+		}
+		if next < 0 || lb.Tokens > clusterLBs[next].Tokens {
+			next = i
+		}
+	}
+	return next
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"math/rand"
+
+	dcv1 "custome_weightedRR/api/dc/v1"
+)
+
+// Gradient model: every LB advertises its distance in hops to the nearest
+// lightly loaded cluster (queue at or below gradient_low) in the feedback
+// reports. A lightly loaded LB has distance 0, any other one more than the
+// nearest of its neighbors. Overloaded LBs (queue above the threshold of an
+// edge) forward downhill, to the neighbor with the smallest distance, and a
+// request forwarded by a neighbor travels on downhill until it reaches a
+// lightly loaded cluster, so that a flash crowd reaches idle clusters
+// several hops away.
+type gradientModel struct {
+	from string // Adjacent LB the current request came from, not sent back to
+}
+
+// Distances at or beyond the horizon mean that no lightly loaded cluster is
+// reachable; requests are not forwarded more often than this either.
+const gradientHorizon = 16
+
+func (p *gradientModel) Forward() bool {
+	p.from = ""
+	return anyOverThreshold()
+}
+
+// Requests forwarded by a neighbor stay in a lightly loaded cluster
+// and travel on downhill otherwise
+func (p *gradientModel) Transit(from string, hops int) bool {
+	p.from = from
+	return hops < gradientHorizon && queue > config.GradientLow
+}
+
+// Healthy neighbor with the smallest distance below the own one (random
+// among ties), or -1 to process the request in this cluster
+func (p *gradientModel) Select(skip []bool) int {
+	own := ownDistance()
+	var next []int
+	best := own
+	for i, lb := range clusterLBs {
+		if !candidate(i, skip) || lb.Distance < 0 || lb.ID == p.from {
+			continue
+		}
+		// Own overload only spills over edges whose threshold is exceeded
+		if p.from == "" && !overThreshold(i) {
+			continue
+		}
+		if lb.Distance < best {
+			best, next = lb.Distance, next[:0]
+		}
+		if lb.Distance == best && best < own {
+			next = append(next, i)
+		}
+	}
+	if len(next) == 0 {
+		return -1
+	}
+	return next[rand.Intn(len(next))]
+}
+
+// The distances are read directly from clusterLBs in Select
+func (p *gradientModel) Calculate(next_queue int, num int) {}
+
+func (p *gradientModel) annotate(num int, r *dcv1.LoadReport, answer bool) {
+	r.Gradient = int32(ownDistance()) + 1
+}
+
+// Hops from this LB to the nearest lightly loaded cluster, up to the horizon
+func ownDistance() int {
+	if queue <= config.GradientLow {
+		return 0
+	}
+	distance := gradientHorizon
+	for _, lb := range clusterLBs {
+		if lb.IsHealthy && lb.Distance >= 0 {
+			distance = min(distance, lb.Distance+1)
+		}
+	}
+	return distance
+}
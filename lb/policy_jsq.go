@@ -0,0 +1,44 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Join the shortest queue of d sampled neighbors, JSQ(d) (baseline)
+// Forward when the own queue exceeds the threshold; jsq_d healthy adjacent
+// LBs whose edge threshold is exceeded are sampled uniformly without
+// replacement (all of them if there are fewer), and the request goes to the
+// one with the fewest estimated waiting sessions (estimator.go)
+type joinShortestQueue struct{}
+
+func (p *joinShortestQueue) Forward() bool {
+	return anyOverThreshold()
+}
+
+func (p *joinShortestQueue) Select(skip []bool) int {
+	var candidates []int
+	for i := range clusterLBs {
+		if candidate(i, skip) && overThreshold(i) {
+			candidates = append(candidates, i)
+		}
+	}
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > config.JSQD {
+		candidates = candidates[:config.JSQD]
+	}
+
+	// The sample is in random order, so the first minimum breaks ties at random
+	next, minVal := -1, math.MaxInt
+	for _, i := range candidates {
+		if load := neighborLoad(i); load < minVal {
+			next, minVal = i, load
+		}
+	}
+	return next
+}
+
+// The estimated loads are used directly in Select
+func (p *joinShortestQueue) Calculate(next_queue int, num int) {}
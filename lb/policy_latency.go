@@ -0,0 +1,49 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// Latency-aware DC: the continuous flow of the flow policy, discounted by
+// the measured round trip to the neighbor (RTT, loadstats.go),
+//
+//	flow = kappa (own load - neighbor load) / cost × g(RTT)
+//
+// with the cost function g of rtt_cost and rtt_scale, so that far
+// neighbors (delays injected by delayController.py) get less of the load.
+// Until the RTT of a neighbor is measured, g is 1.
+type latencyDC struct {
+	flowDC
+}
+
+func (p *latencyDC) Calculate(next_queue int, num int) {
+	p.refill(time.Now())
+	lb := &clusterLBs[num]
+	setFlow(num, edgeKappa(num)*loadDiff(num, next_queue)/lb.Edge.CostOrDefault()*rttDiscount(lb.RTT))
+}
+
+// Values of rtt_cost
+const (
+	rttCostLinear = "linear"
+	rttCostExp    = "exp"
+	rttCostCutoff = "cutoff"
+)
+
+// Factor of the flow to a neighbor at the round trip rtt, from 1 (near) to 0 (far)
+func rttDiscount(rtt time.Duration) float64 {
+	if rtt <= 0 {
+		return 1
+	}
+	x := float64(rtt) / float64(time.Duration(config.RTTScale)*time.Millisecond)
+	switch config.RTTCost {
+	case rttCostExp:
+		return math.Exp(-x)
+	case rttCostCutoff:
+		if x > 1 {
+			return 0
+		}
+		return 1
+	}
+	return 1 / (1 + x)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Least Connection method
+// Forward when the own queue exceeds the threshold, to the adjacent LB
+// with the fewest waiting sessions
+type leastConn struct{}
+
+func (p *leastConn) Forward() bool {
+	return anyOverThreshold()
+}
+
+// Least Connection method (distribution to adjacent LBs)
+func (p *leastConn) Select(skip []bool) int {
+	// Find the minimum estimated number of waiting sessions (estimator.go) among
+	// healthy adjacent LBs whose edge threshold is exceeded and keep
+	// candidates with the same value
+	minVal := math.MaxInt
+	var minIdxs []int
+	for i := range clusterLBs {
+		load := neighborLoad(i)
+		if !candidate(i, skip) || !overThreshold(i) {
+			continue
+		}
+		if load < minVal {
+			minVal = load
+			minIdxs = minIdxs[:0]
+		}
+		if load == minVal {
+			minIdxs = append(minIdxs, i)
+		}
+	}
+
+	// If no adjacent LB is a candidate (none of the adjacent LBs are available)
+	if len(minIdxs) == 0 {
+		return -1
+	}
+
+	// select one from a Random
+	return minIdxs[rand.Intn(len(minIdxs))]
+}
+
+// The estimated loads are used directly in Select
+func (p *leastConn) Calculate(next_queue int, num int) {}
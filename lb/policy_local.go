@@ -0,0 +1,17 @@
+package main
+
+// Local processing only (baseline)
+// Never forward; every request is processed by the own web servers, while
+// the feedback is still exchanged and recorded like with the other policies
+type localOnly struct{}
+
+func (p *localOnly) Forward() bool {
+	return false
+}
+
+func (p *localOnly) Select(skip []bool) int {
+	return -1
+}
+
+// Feedback information is recorded but not used
+func (p *localOnly) Calculate(next_queue int, num int) {}
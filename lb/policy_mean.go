@@ -0,0 +1,42 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Forward only while the own queue exceeds the global mean load estimated
+// by the gossip (gossip.go) by mean_margin sessions, to the least loaded
+// healthy adjacent LB below the own queue
+type aboveMean struct{}
+
+func (p *aboveMean) Forward() bool {
+	mean, _ := gossipEstimate(time.Now())
+	return float64(queue) > mean+config.MeanMargin
+}
+
+func (p *aboveMean) Select(skip []bool) int {
+	minVal := math.MaxInt
+	var minIdxs []int
+	for i := range clusterLBs {
+		load := neighborLoad(i)
+		if !candidate(i, skip) || load >= queue {
+			continue
+		}
+		if load < minVal {
+			minVal = load
+			minIdxs = minIdxs[:0]
+		}
+		if load == minVal {
+			minIdxs = append(minIdxs, i)
+		}
+	}
+	if len(minIdxs) == 0 {
+		return -1
+	}
+	return minIdxs[rand.Intn(len(minIdxs))]
+}
+
+// The estimated loads are used directly in Select
+func (p *aboveMean) Calculate(next_queue int, num int) {}
@@ -0,0 +1,69 @@
+package main
+
+import "time"
+
+// Queueing model: every cluster is an M/M/c queue of its c web servers,
+// each serving mu requests per second, measured from the responses
+// (loadstats.go) and exchanged in the reports. A request that finds q
+// requests ahead of it waits, once all web servers are busy, for q-c+1
+// departures at rate c mu, and is then served in 1/mu:
+//
+//	W(q) = max(q-c+1, 0)/(c mu) + 1/mu
+//
+// A request is forwarded only when the predicted time at a neighbor plus
+// the round trip to it (measured on the feedback) beats the own predicted
+// time, to the neighbor where it is smallest. Unlike the raw queue
+// difference, this accounts for the speed of the web servers and the
+// distance of the clusters. A neighbor that has not measured its rate yet
+// (it has been idle) is assumed to be as fast as the own web servers.
+type queueingModel struct{}
+
+func (p *queueingModel) Forward() bool {
+	return p.best(nil) >= 0
+}
+
+// The neighbors are evaluated again rather than taken from Forward, as their
+// health may have changed in between
+func (p *queueingModel) Select(skip []bool) int {
+	return p.best(skip)
+}
+
+// Candidate adjacent LB with the smallest predicted time plus round trip, if
+// it beats the own predicted time, or -1
+func (p *queueingModel) best(skip []bool) int {
+	// The current request is already counted in the own queue
+	best, ok := mmcWait(queue-1, len(webServers), serverRate)
+	if !ok {
+		return -1
+	}
+	next := -1
+	for i := range clusterLBs {
+		lb := &clusterLBs[i]
+		rate := lb.ServerRate
+		if rate == 0 {
+			rate = serverRate
+		}
+		wait, ok := mmcWait(neighborLoad(i), lb.Servers, rate)
+		if !ok {
+			continue
+		}
+		lb.Wait = wait
+		if t := wait + float64(lb.RTT)/float64(time.Millisecond); candidate(i, skip) && t < best {
+			best, next = t, i
+		}
+	}
+	return next
+}
+
+// The latest reports are used directly in Forward and Select
+func (p *queueingModel) Calculate(next_queue int, num int) {}
+
+// Predicted time [ms] from arrival to response of a request finding ahead
+// requests in an M/M/c queue; ok is false until the rate is measured
+func mmcWait(ahead, servers int, rate float64) (wait float64, ok bool) {
+	if servers <= 0 || rate <= 0 {
+		return 0, false
+	}
+	queued := float64(max(ahead-servers+1, 0))
+	return 1000 * (queued/(float64(servers)*rate) + 1/rate), true
+}
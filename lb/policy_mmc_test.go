@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestQueueingModelSelect(t *testing.T) {
+	tests := []struct {
+		name    string
+		healthy []bool // of the neighbors after Forward
+		skip    []bool
+		want    int
+	}{
+		{name: "best neighbor", healthy: []bool{true, true}, want: 0},
+		{name: "best neighbor went down", healthy: []bool{false, true}, want: 1},
+		{name: "all neighbors went down", healthy: []bool{false, false}, want: -1},
+		{name: "best neighbor skipped", healthy: []bool{true, true}, skip: []bool{true, false}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldServers, oldRate := webServers, serverRate
+			t.Cleanup(func() { webServers, serverRate = oldServers, oldRate })
+			webServers, serverRate = make([]webServer, 2), 10
+
+			lbs := []LoadBalancer{
+				{ID: "cluster1", IsHealthy: true, Servers: 2, ServerRate: 10, Data: 0},
+				{ID: "cluster2", IsHealthy: true, Servers: 2, ServerRate: 10, Data: 4},
+			}
+			withNeighbors(t, defaultConfig(), lbs, 20)
+
+			p := &queueingModel{}
+			if !p.Forward() {
+				t.Fatal("Forward = false with an overloaded own queue")
+			}
+			for i, h := range tt.healthy {
+				clusterLBs[i].IsHealthy = h
+			}
+			if got := p.Select(tt.skip); got != tt.want {
+				t.Errorf("Select = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// PID control of the forwarding rate: the load difference to each adjacent
+// LB (divided by the cost of the edge) is the error of a PID controller,
+// whose output is the flow to the neighbor in requests per feedback
+// interval, forwarded with the tokens of flowDC,
+//
+//	u = pid_kp e + pid_ki ∫e dt + pid_kd de/dt
+//
+// instead of forwarding everything once the queue exceeds the threshold.
+// The output is limited to [0, pid_max] (no upper limit with pid_max 0), and
+// the integral is frozen while the output is saturated in the direction of
+// the error (anti-windup), so that a long overload of the neighbor does not
+// delay forwarding once it is relieved.
+type pidDC struct {
+	flowDC
+}
+
+// State of the PID controller of an edge
+type pidState struct {
+	Integral float64   // ∫e dt [requests s]
+	Error    float64   // Error of the last step
+	Slope    float64   // de/dt of the last step [requests/s]
+	At       time.Time // Last step
+	P, I, D  float64   // Terms of the output of the last report [requests per feedback interval]
+}
+
+// The integral and the derivative step once per round (newRound); reports
+// within a round only update the proportional term.
+func (p *pidDC) Calculate(next_queue int, num int) {
+	now := time.Now()
+	p.refill(now)
+	lb := &clusterLBs[num]
+	s := &lb.PID
+	e := loadDiff(num, next_queue) / lb.Edge.CostOrDefault()
+
+	integral := s.Integral
+	last := s.At
+	step := newRound(&s.At, now)
+	if step && !last.IsZero() {
+		dt := now.Sub(last).Seconds()
+		s.Slope = (e - s.Error) / dt
+		integral += e * dt
+	}
+
+	output := func(integral float64) float64 {
+		return config.PIDKp*e + config.PIDKi*integral + config.PIDKd*s.Slope
+	}
+	u := output(integral)
+	// Anti-windup: keep the integral while saturated in the direction of the error
+	if (u < 0 && e < 0) || (config.PIDMax > 0 && u > config.PIDMax && e > 0) {
+		integral = s.Integral
+		u = output(integral)
+	}
+	if step {
+		s.Integral, s.Error = integral, e
+	}
+	s.P, s.I, s.D = config.PIDKp*e, config.PIDKi*integral, config.PIDKd*s.Slope
+	for term, v := range map[string]float64{"p": s.P, "i": s.I, "d": s.D} {
+		pidTerms.WithLabelValues(ownNodeID, lb.ID, term).Set(v)
+	}
+
+	if config.PIDMax > 0 {
+		u = math.Min(u, config.PIDMax)
+	}
+	setFlow(num, u)
+}
@@ -0,0 +1,28 @@
+package main
+
+import "math/rand"
+
+// Uniform random method (baseline)
+// Forward when the own queue exceeds the threshold, to a healthy adjacent LB
+// whose edge threshold is exceeded, chosen uniformly at random
+type randomNeighbor struct{}
+
+func (p *randomNeighbor) Forward() bool {
+	return anyOverThreshold()
+}
+
+func (p *randomNeighbor) Select(skip []bool) int {
+	var candidates []int
+	for i := range clusterLBs {
+		if candidate(i, skip) && overThreshold(i) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return -1
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+// Feedback information is recorded but not used
+func (p *randomNeighbor) Calculate(next_queue int, num int) {}
@@ -0,0 +1,27 @@
+package main
+
+// RR (N-Co) method
+// Forward when the own queue exceeds the threshold, to adjacent LBs in turn
+type roundRobin struct {
+	adjacentIndex int
+}
+
+func (p *roundRobin) Forward() bool {
+	return anyOverThreshold()
+}
+
+// Round Robin between clusters (distribution to adjacent LBs)
+// Unhealthy or skipped adjacent LBs and edges whose threshold is not exceeded are skipped
+func (p *roundRobin) Select(skip []bool) int {
+	for range clusterLBs {
+		next := p.adjacentIndex % len(clusterLBs)
+		p.adjacentIndex = (next + 1) % len(clusterLBs)
+		if candidate(next, skip) && overThreshold(next) {
+			return next
+		}
+	}
+	return -1
+}
+
+// Feedback information is recorded but not used
+func (p *roundRobin) Calculate(next_queue int, num int) {}
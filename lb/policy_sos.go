@@ -0,0 +1,31 @@
+package main
+
+import "time"
+
+// Second-order diffusion (SOS): the flow of each edge keeps a momentum of
+// the flow of the previous round,
+//
+//	y(t) = (beta - 1) y(t-1) + beta kappa (own load - neighbor load)
+//
+// which converges in about the square root of the rounds of first-order
+// diffusion on sparse graphs for beta near 2/(1+sqrt(1-gamma^2)), gamma
+// being the convergence factor of first-order diffusion (tools/stability
+// prints it). beta 1 is the flow policy. Positive flows are forwarded with
+// the tokens of flowDC.
+type sosDC struct {
+	flowDC
+}
+
+// Reports within a round (newRound) update its flow from the same momentum
+func (p *sosDC) Calculate(next_queue int, num int) {
+	now := time.Now()
+	p.refill(now)
+	lb := &clusterLBs[num]
+	if newRound(&lb.RoundAt, now) {
+		lb.PrevFlow = lb.LastFlow
+	}
+
+	flow := (config.Beta-1)*lb.PrevFlow + config.Beta*edgeKappa(num)*loadDiff(num, next_queue)/lb.Edge.CostOrDefault()
+	lb.LastFlow = flow
+	setFlow(num, flow)
+}
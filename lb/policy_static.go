@@ -0,0 +1,37 @@
+package main
+
+import "math"
+
+// Static weights proportional to the capacity (baseline)
+// Forward when the own queue exceeds the threshold, with the weighted round
+// robin over the adjacent LBs whose edge threshold is exceeded. The weight of
+// a neighbor is its share of the capacity of all neighbors in percent (at
+// least 1) and does not depend on the loads; neighbors that have not reported
+// a capacity (legacy API) count as capacity 1.
+type staticCapacity struct{}
+
+func (p *staticCapacity) Forward() bool {
+	return anyOverThreshold()
+}
+
+func (p *staticCapacity) Select(skip []bool) int {
+	return WeightedRoundRobin_AdjacentLB(skip, overThreshold)
+}
+
+// Recompute the shares, as the reported capacity of clusterLBs[num] may have changed
+func (p *staticCapacity) Calculate(next_queue int, num int) {
+	total := 0.0
+	for _, lb := range clusterLBs {
+		total += neighborCapacity(lb)
+	}
+	for i := range clusterLBs {
+		clusterLBs[i].Weight = max(1, int(math.Round(100*neighborCapacity(clusterLBs[i])/total)))
+	}
+}
+
+func neighborCapacity(lb LoadBalancer) float64 {
+	if lb.Capacity > 0 {
+		return lb.Capacity
+	}
+	return 1
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func waitForAllLBsAndSyncStart(ctx context.Context, rdb *redis.Client, ownNodeID string, totalLBs int, isLeader bool, redisKey string) {
+	pubsubChannel := "sync_start"
+
+	// Notify own readiness
+	if err := rdb.Set(ctx, redisKey+ownNodeID, "true", 0).Err(); err != nil {
+		log.Fatalf("Redis SET failed: %v", err)
+	}
+	fmt.Println("LB Ready sent:", redisKey+ownNodeID)
+
+	// Subscribe to sync_start (all LBs including leader subscribe)
+	sub := rdb.Subscribe(ctx, pubsubChannel)
+	defer sub.Close()
+
+	// Prepare to receive the first message
+	_, err := sub.Receive(ctx)
+	if err != nil {
+		log.Fatalf("Failed to subscribe to %s: %v", pubsubChannel, err)
+	}
+	ch := sub.Channel()
+
+	if isLeader {
+		// Leader's role: publish sync_start when all are ready
+		fmt.Println("Coordinator waiting for all LB readiness...")
+		for {
+			keys, err := rdb.Keys(ctx, redisKey+"*").Result()
+			if err != nil {
+				log.Printf("Redis KEYS error: %v", err)
+				time.Sleep(1 * time.Second)
+				continue
+			}
+			if len(keys) == totalLBs {
+				fmt.Printf("%d/%d ready. Publishing sync_start...\n", len(keys), totalLBs)
+				break
+			}
+			fmt.Printf("%d/%d ready\n", len(keys), totalLBs)
+			time.Sleep(1 * time.Second)
+		}
+
+		// Send sync_start message
+		err := rdb.Publish(ctx, pubsubChannel, "start").Err()
+		if err != nil {
+			log.Fatalf("Failed to publish sync_start: %v", err)
+		}
+	}
+
+	// Wait for sync_start from all (including leader)
+	fmt.Println("Waiting for sync_start signal...")
+	for msg := range ch {
+		if msg.Payload == "start" {
+			fmt.Println("Received sync_start signal. Proceeding to main.")
+			break
+		}
+	}
+}
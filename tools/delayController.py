@@ -124,6 +124,9 @@ class ClusterInfo:
                 
                 if 'adjacentList' in data[cluster_name]:
                     for adj_cluster, adj_ip in data[cluster_name]['adjacentList'].items():
+                        # Edges with attributes are objects carrying the address
+                        if isinstance(adj_ip, dict):
+                            adj_ip = adj_ip['address']
                         adj_id = adj_cluster.replace('cluster', '')
                         self.adjacency[cluster_id].add(adj_id)
                         self.adjacency_ips[cluster_id][adj_id] = adj_ip
@@ -470,6 +473,7 @@ class ClusterInfo:
                 fail_count += 1
         
         print(f"\nSettings applied: Success {success_count}, Fail {fail_count}")
+        self.save_delays()
         return fail_count == 0
     
     def _apply_delay_single_direction(self, src_id: str, dst_id: str, delay_ms: int) -> bool:
@@ -578,10 +582,21 @@ class ClusterInfo:
         # Clear current_delays if all removals were successful
         if fail_count == 0:
             self.current_delays.clear()
+            self.save_delays()
         
         print(f"\nRemoval complete: Success {success_count}, Fail {fail_count}")
         return fail_count == 0
     
+    def save_delays(self, json_path: str = "../json/linkDelays.json"):
+        """Record the current link delays for tools/stability ({"cluster0-cluster1": delay_ms})"""
+        delays = {f"cluster{src_id}-cluster{dst_id}": delay_ms
+                  for (src_id, dst_id), delay_ms in sorted(self.current_delays.items())}
+        try:
+            with open(json_path, 'w') as f:
+                json.dump(delays, f, indent=2)
+        except OSError as e:
+            print(f"Warning: Failed to write {json_path}: {e}", file=sys.stderr)
+    
     def _remove_delay_single_container(self, cluster_id: str) -> bool:
         """
         Remove delay settings from a single container
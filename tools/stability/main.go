@@ -0,0 +1,378 @@
+// Recommend kappa and the feedback interval for an adjacency list
+//
+// Usage: go run ./tools/stability [-file ./json/adjacentList.json] [-k 0.2] [-t 100] [-kappa-mode global]
+//
+//	[-delay 20] [-delays ./json/linkDelays.json]
+//
+// DC moves kappa * (x_i - x_j) requests over every edge per feedback round,
+// so the loads follow x(t+1) = (I - kappa L) x(t) with the graph Laplacian L.
+// The imbalance decays with the factor max|1 - kappa lambda| over the
+// non-zero eigenvalues lambda of L: the largest eigenvalue bounds kappa,
+// and the smallest (the spectral gap) bounds the convergence time. Link
+// delays (set by delayController.py, which records them in -delays) make
+// the reports stale by whole feedback rounds; the chosen configuration is
+// simulated with them to detect oscillations.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"custome_weightedRR/topology"
+)
+
+const (
+	kappaGlobal     = "global"
+	kappaMetropolis = "metropolis"
+)
+
+func main() {
+	var file, kappaMode, delaysFile string
+	var kappa, delay, tolerance float64
+	var feedback, maxRounds int
+
+	flag.StringVar(&file, "file", "./json/adjacentList.json", "adjacency list")
+	flag.Float64Var(&kappa, "kappa", 0, "diffusion coefficient to check (0: only recommend)")
+	flag.Float64Var(&kappa, "k", 0, "shorthand for -kappa")
+	flag.StringVar(&kappaMode, "kappa-mode", kappaGlobal, "diffusion coefficient of each edge (global, metropolis)")
+	flag.IntVar(&feedback, "feedback", 100, "feedback interval [ms]")
+	flag.IntVar(&feedback, "t", 100, "shorthand for -feedback")
+	flag.Float64Var(&delay, "delay", 0, "one-way delay of every link [ms]")
+	flag.StringVar(&delaysFile, "delays", "", "delays of single links written by delayController.py (override -delay)")
+	flag.Float64Var(&tolerance, "tolerance", 0.01, "remaining fraction of the initial imbalance counted as converged")
+	flag.IntVar(&maxRounds, "max-rounds", 10000, "feedback rounds simulated at most")
+	flag.Parse()
+
+	if feedback <= 0 || kappa < 0 || delay < 0 || tolerance <= 0 || tolerance >= 1 {
+		fmt.Fprintln(os.Stderr, "-feedback must be positive, -kappa and -delay 0 or more, -tolerance in (0, 1)")
+		os.Exit(2)
+	}
+	if kappaMode != kappaGlobal && kappaMode != kappaMetropolis {
+		fmt.Fprintf(os.Stderr, "-kappa-mode must be %s or %s (got %q)\n", kappaGlobal, kappaMetropolis, kappaMode)
+		os.Exit(2)
+	}
+
+	topo, err := topology.Load(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(topo.Components()) > 1 {
+		fmt.Fprintf(os.Stderr, "%s: the graph is not connected, the loads cannot converge\n", file)
+		os.Exit(1)
+	}
+	if len(topo) < 2 {
+		fmt.Fprintf(os.Stderr, "%s: nothing to balance with a single node\n", file)
+		os.Exit(1)
+	}
+
+	delays := map[[2]string]float64{}
+	if delaysFile != "" {
+		if delays, err = loadDelays(delaysFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	linkDelay := func(a, b string) float64 {
+		if d, ok := delays[[2]string{a, b}]; ok {
+			return d
+		}
+		return delay
+	}
+
+	// Unweighted graph
+	ids, l := topology.Laplacian(topo, func(a, b string) float64 { return 1 })
+	lambda := topology.SymmetricEigenvalues(l)
+	l2, lmax := lambda[1], lambda[len(lambda)-1]
+	edges, maxDegree, maxDelay := 0, 0, 0.0
+	for _, id := range ids {
+		maxDegree = max(maxDegree, len(topo[id].AdjacentList))
+		for nid := range topo[id].AdjacentList {
+			if topology.LessID(id, nid) {
+				edges++
+				maxDelay = max(maxDelay, linkDelay(id, nid))
+			}
+		}
+	}
+	stale := staleRounds(maxDelay, feedback)
+
+	fmt.Printf("%s: %d nodes, %d edges, max degree %d\n", file, len(ids), edges, maxDegree)
+	fmt.Printf("Laplacian: lambda_2 = %.4f (spectral gap), lambda_max = %.4f, lambda_2/lambda_max = %.4f\n", l2, lmax, l2/lmax)
+
+	fmt.Printf("\nkappa for every edge (kappa_mode %s):\n", kappaGlobal)
+	fmt.Printf("  stable          kappa < 2/lambda_max = %.4f\n", 2/lmax)
+	fmt.Printf("  no oscillation  kappa <= 1/lambda_max = %.4f\n", 1/lmax)
+	fastest := 2 / (l2 + lmax)
+	fmt.Printf("  fastest         kappa = 2/(lambda_2+lambda_max) = %.4f (%s%s)\n", fastest, convergence(rho(fastest*l2, fastest*lmax), tolerance, feedback), overshootLabel(fastest*lmax))
+	if stale > 0 {
+		fmt.Printf("  with reports %d round(s) stale (link delay %.0fms, feedback %dms):\n", stale, maxDelay, feedback)
+		fmt.Printf("                  kappa < 2 sin(pi/(2(2k+1)))/lambda_max = %.4f (conservative)\n", delayedLimit(stale)/lmax)
+	}
+	recommended := delayedLimit(stale) / 2 / lmax
+	fmt.Printf("  recommended     kappa = %.4f (%s)\n", recommended, convergence(rho(recommended*l2, recommended*lmax), tolerance, feedback))
+	printBeta(rho(recommended*l2, recommended*lmax), tolerance, feedback)
+	if maxDelay > 0 {
+		fmt.Printf("\nfeedback interval: -t %d or more keeps the reports less than one round stale (max link delay %.0fms)\n", int(math.Ceil(maxDelay)), maxDelay)
+	}
+
+	// Chosen configuration, with the per-edge attributes of the adjacency list.
+	// Without -kappa, it is only checked when the edges set their own kappa
+	if kappa == 0 && kappaMode == kappaGlobal && !anyEdgeKappa(topo) {
+		return
+	}
+	weight := edgeWeight(topo, kappa, kappaMode)
+	_, lw := topology.Laplacian(topo, weight)
+	mu := topology.SymmetricEigenvalues(lw)
+	m2, mmax := mu[1], mu[len(mu)-1]
+
+	fmt.Printf("\nchosen: -k %g -kappa-mode %s -t %d", kappa, kappaMode, feedback)
+	if maxDelay > 0 {
+		fmt.Printf(", link delays up to %.0fms", maxDelay)
+	}
+	fmt.Println()
+	fmt.Printf("  kappa*lambda of the edge weights: %.4f .. %.4f\n", m2, mmax)
+	var warnings []string
+	if mmax > 0 {
+		fmt.Printf("  without delay   %s\n", convergence(rho(m2, mmax), tolerance, feedback))
+		printBeta(rho(m2, mmax), tolerance, feedback)
+	}
+	switch {
+	case mmax == 0:
+		warnings = append(warnings, "kappa is 0 on every edge: DC does not forward (weights stay 0)")
+	case mmax >= 2:
+		warnings = append(warnings, fmt.Sprintf("diverges: kappa*lambda_max = %.3f >= 2 even without delay", mmax))
+	case mmax > 1:
+		// The mode of lambda_max changes its sign every round and decays by |1 - kappa*lambda_max|
+		fmt.Printf("  overshoots      kappa*lambda_max = %.3f > 1, the alternating mode keeps %.2f of its amplitude per round\n", mmax, mmax-1)
+		if mmax > 1.5 {
+			warnings = append(warnings, fmt.Sprintf("oscillates: the load swings between neighbors and keeps %.0f%% of the swing every round", 100*(mmax-1)))
+		}
+	}
+
+	if mmax > 0 {
+		sim := simulate(topo, ids, weight, func(a, b string) int { return staleRounds(linkDelay(a, b), feedback) }, tolerance, maxRounds)
+		fmt.Printf("  simulated       ")
+		switch {
+		case sim.diverged:
+			fmt.Println("diverges")
+			warnings = append(warnings, "diverges in the simulation with the link delays")
+		case sim.rounds < 0:
+			fmt.Printf("not converged within %d rounds\n", maxRounds)
+			warnings = append(warnings, "does not converge in the simulation; lower kappa or raise -t")
+		default:
+			fmt.Printf("%d rounds (%.1fs) to %g of a flash crowd on %s, overshoot %.1f%%\n",
+				sim.rounds, float64(sim.rounds*feedback)/1000, tolerance, sim.worst, 100*sim.overshoot)
+			if sim.overshoot > 0.1 {
+				warnings = append(warnings, fmt.Sprintf("likely to oscillate: the simulated load overshoots by %.0f%% of the initial imbalance", 100*sim.overshoot))
+			}
+		}
+	}
+
+	for _, w := range warnings {
+		fmt.Printf("warning: %s\n", w)
+	}
+}
+
+// ", overshoots" when the mode of lambda_max changes its sign every round,
+// that is kappa*lambda_max > 1
+func overshootLabel(kappaLambdaMax float64) string {
+	if kappaLambdaMax > 1 {
+		return ", overshoots"
+	}
+	return ""
+}
+
+// Whether some edge of the adjacency list sets its own kappa
+func anyEdgeKappa(topo topology.Topology) bool {
+	for _, node := range topo {
+		for _, edge := range node.AdjacentList {
+			if edge.Kappa != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Weight of the edge a-b in the Laplacian as the LBs compute it (edge.go):
+// the kappa attribute, the Metropolis-Hastings weight or the global kappa,
+// divided by the cost; the mean of both directions if they differ.
+func edgeWeight(topo topology.Topology, kappa float64, mode string) func(a, b string) float64 {
+	direction := func(a, b string) float64 {
+		attrs := topo[a].AdjacentList[b].EdgeAttrs
+		k := kappa
+		if attrs.Kappa != nil {
+			k = *attrs.Kappa
+		} else if mode == kappaMetropolis {
+			k = 1 / float64(1+max(len(topo[a].AdjacentList), len(topo[b].AdjacentList)))
+		}
+		return k / attrs.CostOrDefault()
+	}
+	return func(a, b string) float64 {
+		return (direction(a, b) + direction(b, a)) / 2
+	}
+}
+
+// Largest eigenvalue magnitude of I - kappa L except the consensus mode,
+// from the smallest and largest non-zero eigenvalue of kappa L
+func rho(m2, mmax float64) float64 {
+	return max(math.Abs(1-m2), math.Abs(1-mmax))
+}
+
+func convergence(r float64, tolerance float64, feedback int) string {
+	if r >= 1 {
+		return "does not converge"
+	}
+	if r == 0 {
+		return "1 round"
+	}
+	rounds := math.Ceil(math.Log(tolerance) / math.Log(r))
+	return fmt.Sprintf("%.0f rounds = %.1fs to %g of the imbalance", rounds, rounds*float64(feedback)/1000, tolerance)
+}
+
+// Optimal momentum of the second-order diffusion (sos policy) for the
+// convergence factor gamma of first-order diffusion; the imbalance then
+// decays with sqrt(beta - 1) per round
+func printBeta(gamma float64, tolerance float64, feedback int) {
+	if gamma >= 1 || gamma == 0 {
+		return
+	}
+	beta := 2 / (1 + math.Sqrt(1-gamma*gamma))
+	fmt.Printf("    sos policy    -beta %.4f (%s)\n", beta, convergence(math.Sqrt(beta-1), tolerance, feedback))
+}
+
+// Limit of kappa*lambda_max when every report is k rounds stale: the roots
+// of z^(k+1) - z^k + kappa*lambda stay in the unit circle below 2 sin(pi/(2(2k+1)))
+func delayedLimit(k int) float64 {
+	return 2 * math.Sin(math.Pi/float64(2*(2*k+1)))
+}
+
+// Whole feedback rounds a report is older than the own load when it is used
+func staleRounds(delay float64, feedback int) int {
+	return int(math.Ceil(delay / float64(feedback)))
+}
+
+type simulation struct {
+	rounds    int     // Rounds until the imbalance stays below the tolerance, -1 if never
+	overshoot float64 // Largest swing of the crowded node below the mean, relative to the initial imbalance
+	worst     string  // Node of the flash crowd with the slowest convergence
+	diverged  bool
+}
+
+// Simulate DC after a flash crowd on each node in turn and keep the slowest.
+// Every round each node forwards kappa*(own load - reported load) to the
+// neighbors it exceeds, where the report of the edge a-b is stale(a, b)
+// rounds old; the total load is conserved. A crowd is converged, and its
+// simulation stops, once every round that the stale reports still refer to
+// is within the tolerance.
+func simulate(topo topology.Topology, ids []string, weight func(a, b string) float64, stale func(a, b string) int, tolerance float64, maxRounds int) simulation {
+	type edge struct {
+		a, b  int
+		w     float64
+		stale int
+	}
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+	var edges []edge
+	history := 1
+	for _, a := range ids {
+		for b := range topo[a].AdjacentList {
+			if topology.LessID(a, b) {
+				e := edge{a: index[a], b: index[b], w: weight(a, b), stale: stale(a, b)}
+				edges = append(edges, e)
+				history = max(history, e.stale+1)
+			}
+		}
+	}
+
+	n := len(ids)
+	var worst simulation
+	for crowd := range ids {
+		// past[t%history] holds the loads of round t
+		past := make([][]float64, history)
+		for t := range past {
+			past[t] = make([]float64, n)
+			past[t][crowd] = float64(n)
+		}
+		mean := 1.0
+		initial := float64(n) - mean
+
+		result := simulation{rounds: -1, worst: ids[crowd]}
+		below := 0
+		for t := 0; t < maxRounds; t++ {
+			x := past[t%history]
+			next := append([]float64(nil), x...)
+			for _, e := range edges {
+				// Each side compares its own load with the stale report of the other
+				if f := e.w * (x[e.a] - past[(t-e.stale+history*maxRounds)%history][e.b]); f > 0 {
+					next[e.a] -= f
+					next[e.b] += f
+				}
+				if f := e.w * (x[e.b] - past[(t-e.stale+history*maxRounds)%history][e.a]); f > 0 {
+					next[e.b] -= f
+					next[e.a] += f
+				}
+			}
+			past[(t+1)%history] = next
+
+			imbalance := 0.0
+			for _, v := range next {
+				imbalance = max(imbalance, math.Abs(v-mean))
+			}
+			if math.IsNaN(imbalance) || imbalance > 1e6*initial {
+				result.diverged = true
+				break
+			}
+			result.overshoot = max(result.overshoot, (mean-next[crowd])/initial)
+			if imbalance <= tolerance*initial {
+				if below == 0 {
+					result.rounds = t + 1
+				}
+				below++
+				if below >= history {
+					break
+				}
+			} else {
+				below = 0
+				result.rounds = -1
+			}
+		}
+
+		if result.diverged || result.rounds < 0 {
+			return result
+		}
+		if result.rounds > worst.rounds {
+			worst.rounds, worst.worst = result.rounds, result.worst
+		}
+		worst.overshoot = max(worst.overshoot, result.overshoot)
+	}
+	return worst
+}
+
+// Delays of single links written by delayController.py: {"cluster0-cluster1": 20} [ms]
+func loadDelays(path string) (map[[2]string]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]float64
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	delays := make(map[[2]string]float64, len(raw))
+	for link, d := range raw {
+		a, b, ok := strings.Cut(link, "-")
+		if !ok || d < 0 {
+			return nil, fmt.Errorf("%s: invalid link delay %q: %g", path, link, d)
+		}
+		delays[[2]string{a, b}] = d
+		delays[[2]string{b, a}] = d
+	}
+	return delays, nil
+}
@@ -0,0 +1,60 @@
+// Check json/adjacentList.json before starting the LBs
+//
+// Usage: go run ./tools/topocheck [-file ./json/adjacentList.json] [-node cluster0,cluster1] [-strict]
+// Exits with 1 if the topology has errors (warnings are only printed).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"custome_weightedRR/topology"
+)
+
+func main() {
+	var file, nodes string
+	var ports topology.Ports
+	var strict bool
+
+	flag.StringVar(&file, "file", "./json/adjacentList.json", "adjacency list to check")
+	flag.StringVar(&nodes, "node", "", "comma-separated node IDs that must be present (e.g. the leader)")
+	flag.IntVar(&ports.HTTP, "http-port", 8001, "default port of cluster_lb and adjacentList addresses")
+	flag.IntVar(&ports.GRPC, "grpc-port", 50051, "default gRPC port")
+	flag.IntVar(&ports.Backend, "backend-port", 80, "default port of web servers")
+	flag.BoolVar(&strict, "strict", false, "treat warnings as errors")
+	flag.Parse()
+
+	topo, err := topology.Load(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var required []string
+	for _, id := range strings.Split(nodes, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			required = append(required, id)
+		}
+	}
+
+	report := topology.Check(topo, ports, required...)
+	fmt.Print(report)
+
+	edges := make(map[[2]string]bool)
+	for id, node := range topo {
+		for nid := range node.AdjacentList {
+			if topology.LessID(id, nid) {
+				edges[[2]string{id, nid}] = true
+			} else {
+				edges[[2]string{nid, id}] = true
+			}
+		}
+	}
+	fmt.Printf("%s: %d nodes, %d edges, %d errors, %d warnings\n", file, len(topo), len(edges), len(report.Errors), len(report.Warnings))
+
+	if len(report.Errors) > 0 || (strict && len(report.Warnings) > 0) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,136 @@
+package topology
+
+import (
+	"math"
+	"sort"
+)
+
+// Laplacian of the adjacency graph with the edge weights given by weight
+// (called once per undirected edge with LessID(a, b)). Rows and columns
+// follow the returned node IDs in SortIDs order.
+func Laplacian(t Topology, weight func(a, b string) float64) (ids []string, l [][]float64) {
+	ids = t.IDs()
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	l = make([][]float64, len(ids))
+	for i := range l {
+		l[i] = make([]float64, len(ids))
+	}
+	for _, a := range ids {
+		for b := range t[a].AdjacentList {
+			j, ok := index[b]
+			if !ok || !LessID(a, b) {
+				continue
+			}
+			i := index[a]
+			w := weight(a, b)
+			l[i][j] -= w
+			l[j][i] -= w
+			l[i][i] += w
+			l[j][j] += w
+		}
+	}
+	return ids, l
+}
+
+// Eigenvalues of the symmetric matrix m in ascending order (cyclic Jacobi)
+func SymmetricEigenvalues(m [][]float64) []float64 {
+	n := len(m)
+	a := make([][]float64, n)
+	for i := range m {
+		a[i] = append([]float64(nil), m[i]...)
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		off := 0.0
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += a[i][j] * a[i][j]
+			}
+		}
+		if off < 1e-22 {
+			break
+		}
+
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if math.Abs(a[p][q]) < 1e-300 {
+					continue
+				}
+				// Rotation that zeroes a[p][q]
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+			}
+		}
+	}
+
+	values := make([]float64, n)
+	for i := range a {
+		values[i] = a[i][i]
+	}
+	sort.Float64s(values)
+	return values
+}
+
+// Solve the Laplacian system l x = b of a connected graph, where the entries
+// of b sum to 0. The solution is unique up to a constant; the one with
+// x[len(x)-1] = 0 is returned (Gaussian elimination with the last node
+// grounded).
+func SolveLaplacian(l [][]float64, b []float64) []float64 {
+	n := len(l)
+	x := make([]float64, n)
+	if n < 2 {
+		return x
+	}
+
+	// Augmented matrix without the grounded row and column
+	m := n - 1
+	a := make([][]float64, m)
+	for i := range a {
+		a[i] = append(append([]float64(nil), l[i][:m]...), b[i])
+	}
+	for col := 0; col < m; col++ {
+		pivot := col
+		for r := col + 1; r < m; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		if math.Abs(a[col][col]) < 1e-12 {
+			continue // Not connected to the grounded node
+		}
+		for r := col + 1; r < m; r++ {
+			f := a[r][col] / a[col][col]
+			for k := col; k <= m; k++ {
+				a[r][k] -= f * a[col][k]
+			}
+		}
+	}
+	for i := m - 1; i >= 0; i-- {
+		if math.Abs(a[i][i]) < 1e-12 {
+			continue
+		}
+		s := a[i][m]
+		for k := i + 1; k < m; k++ {
+			s -= a[i][k] * x[k]
+		}
+		x[i] = s / a[i][i]
+	}
+	return x
+}
@@ -0,0 +1,138 @@
+package topology
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Topology of n nodes cluster0..cluster{n-1} with an edge between i and i+1,
+// and between the last and the first one with ring
+func chain(n int, ring bool) Topology {
+	t := make(Topology)
+	id := func(i int) string { return fmt.Sprintf("cluster%d", i) }
+	link := func(a, b string) {
+		t[a].AdjacentList[b] = Edge{Address: b}
+		t[b].AdjacentList[a] = Edge{Address: a}
+	}
+	for i := 0; i < n; i++ {
+		t[id(i)] = Node{AdjacentList: map[string]Edge{}, InternalList: map[string]string{}}
+	}
+	for i := 0; i+1 < n; i++ {
+		link(id(i), id(i+1))
+	}
+	if ring && n > 2 {
+		link(id(n-1), id(0))
+	}
+	return t
+}
+
+func unit(a, b string) float64 { return 1 }
+
+func TestSymmetricEigenvalues(t *testing.T) {
+	tests := []struct {
+		name string
+		topo Topology
+		want func(k, n int) float64 // k-th smallest eigenvalue of n nodes
+	}{
+		// Path P_n: 2 - 2cos(k pi / n)
+		{"path 2", chain(2, false), pathEigenvalue},
+		{"path 5", chain(5, false), pathEigenvalue},
+		{"path 12", chain(12, false), pathEigenvalue},
+		// Ring C_n: 2 - 2cos(2 pi j / n), each j and n-j once, in ascending order
+		{"ring 3", chain(3, true), ringEigenvalue},
+		{"ring 6", chain(6, true), ringEigenvalue},
+		{"ring 11", chain(11, true), ringEigenvalue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, l := Laplacian(tt.topo, unit)
+			got := SymmetricEigenvalues(l)
+			n := len(tt.topo)
+			if len(got) != n {
+				t.Fatalf("got %d eigenvalues, want %d", len(got), n)
+			}
+			for k := range got {
+				if want := tt.want(k, n); math.Abs(got[k]-want) > 1e-9 {
+					t.Errorf("eigenvalue %d = %.12f, want %.12f", k, got[k], want)
+				}
+			}
+		})
+	}
+}
+
+func pathEigenvalue(k, n int) float64 {
+	return 2 - 2*math.Cos(float64(k)*math.Pi/float64(n))
+}
+
+func ringEigenvalue(k, n int) float64 {
+	j := (k + 1) / 2 // 0, 1, 1, 2, 2, ...
+	return 2 - 2*math.Cos(2*math.Pi*float64(j)/float64(n))
+}
+
+func TestLaplacian(t *testing.T) {
+	// Weight of the edge a-b is the sum of the numbers of a and b plus 1
+	weight := func(a, b string) float64 {
+		_, i, _ := splitNumber(a)
+		_, j, _ := splitNumber(b)
+		return float64(i + j + 1)
+	}
+	ids, l := Laplacian(chain(3, true), weight)
+
+	wantIDs := []string{"cluster0", "cluster1", "cluster2"}
+	want := [][]float64{
+		{5, -2, -3},
+		{-2, 6, -4},
+		{-3, -4, 7},
+	}
+	if fmt.Sprint(ids) != fmt.Sprint(wantIDs) {
+		t.Errorf("ids = %v, want %v", ids, wantIDs)
+	}
+	if fmt.Sprint(l) != fmt.Sprint(want) {
+		t.Errorf("Laplacian = %v, want %v", l, want)
+	}
+}
+
+func TestSolveLaplacian(t *testing.T) {
+	tests := []struct {
+		name string
+		topo Topology
+		b    []float64
+		want []float64 // nil checks only l x = b and the grounded last node
+	}{
+		{"single node", chain(1, false), []float64{0}, []float64{0}},
+		{"path 3", chain(3, false), []float64{1, 0, -1}, []float64{2, 1, 0}},
+		{"path 4 source in the middle", chain(4, false), []float64{-1, 2, 0, -1}, []float64{1, 2, 1, 0}},
+		{"ring 4", chain(4, true), []float64{1, 0, -1, 0}, []float64{0.5, 0, -0.5, 0}},
+		{"ring 7", chain(7, true), []float64{3, -1, 0, 2, -4, 1, -1}, nil},
+		{"path 9", chain(9, false), []float64{1, 1, 1, 1, -8, 1, 1, 1, 1}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, l := Laplacian(tt.topo, unit)
+			x := SolveLaplacian(l, tt.b)
+			if len(x) != len(tt.b) {
+				t.Fatalf("got %d values, want %d", len(x), len(tt.b))
+			}
+			if x[len(x)-1] != 0 {
+				t.Errorf("last node is %g, want it grounded at 0", x[len(x)-1])
+			}
+			for i := range l {
+				s := 0.0
+				for j := range l[i] {
+					s += l[i][j] * x[j]
+				}
+				if math.Abs(s-tt.b[i]) > 1e-9 {
+					t.Errorf("(l x)[%d] = %g, want %g", i, s, tt.b[i])
+				}
+			}
+			for i, want := range tt.want {
+				if math.Abs(x[i]-want) > 1e-9 {
+					t.Errorf("x[%d] = %g, want %g", i, x[i], want)
+				}
+			}
+		})
+	}
+}
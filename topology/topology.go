@@ -0,0 +1,306 @@
+// Cluster topology read from json/adjacentList.json (tools/adjacentListController.py)
+//
+// The key of each cluster is its node ID, and adjacentList refers to the
+// neighbors by node ID:
+//
+//	{
+//	  "cluster0": {
+//	    "grpc": "172.18.4.2:50051",
+//	    "adjacentList": {"cluster1": "172.18.4.3:8001"},
+//	    "internalList": {"cluster_lb": "172.18.4.2:8001", "web0": "10.0.1.10:80"}
+//	  }
+//	}
+//
+// Addresses are host or host:port (IPv6 as [addr]:port); a missing port is
+// filled with the default of the LB configuration. "grpc" is optional and
+// defaults to the host of cluster_lb with the default gRPC port.
+//
+// An entry of adjacentList is either the address of the neighbor or an
+// object with optional attributes of the edge (see EdgeAttrs):
+//
+//	"cluster1": {"address": "172.18.4.3:8001", "kappa": 0.2, "threshold": 5, "cost": 2, "max_rate": 50}
+package topology
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Key of the own LB in internalList; every other "web*" key is a web server
+const LBKey = "cluster_lb"
+
+type Node struct {
+	GRPC         string            `json:"grpc,omitempty"`
+	AdjacentList map[string]Edge   `json:"adjacentList"`
+	InternalList map[string]string `json:"internalList"`
+}
+
+// Edge to an adjacent node
+type Edge struct {
+	Address string `json:"address"`
+	EdgeAttrs
+}
+
+// Optional attributes of an edge; unset values fall back to the LB configuration
+type EdgeAttrs struct {
+	Kappa     *float64 `json:"kappa,omitempty"`     // Diffusion coefficient of the edge
+	Threshold *int     `json:"threshold,omitempty"` // Threshold of forwarding over the edge
+	Cost      float64  `json:"cost,omitempty"`      // Cost or latency class; the DC flow is divided by it (default 1)
+	MaxRate   float64  `json:"max_rate,omitempty"`  // Maximum forwarding rate [requests/s], 0 is unlimited
+}
+
+// Edges without attributes are written as the plain address
+func (e Edge) MarshalJSON() ([]byte, error) {
+	if e.EdgeAttrs == (EdgeAttrs{}) {
+		return json.Marshal(e.Address)
+	}
+	type edge Edge
+	return json.Marshal(edge(e))
+}
+
+func (e *Edge) UnmarshalJSON(value []byte) error {
+	var addr string
+	if err := json.Unmarshal(value, &addr); err == nil {
+		*e = Edge{Address: addr}
+		return nil
+	}
+	type edge Edge
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.DisallowUnknownFields()
+	var v edge
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("edge must be an address or {\"address\": ..., \"kappa\", \"threshold\", \"cost\", \"max_rate\"}: %v", err)
+	}
+	*e = Edge(v)
+	return nil
+}
+
+// Validate the ranges of the attributes
+func (a EdgeAttrs) Validate() error {
+	switch {
+	case a.Kappa != nil && *a.Kappa < 0:
+		return fmt.Errorf("kappa must be 0 or more (got %g)", *a.Kappa)
+	case a.Threshold != nil && *a.Threshold < 0:
+		return fmt.Errorf("threshold must be 0 or more (got %d)", *a.Threshold)
+	case a.Cost < 0:
+		return fmt.Errorf("cost must be positive (got %g)", a.Cost)
+	case a.MaxRate < 0:
+		return fmt.Errorf("max_rate must be 0 (unlimited) or more (got %g)", a.MaxRate)
+	}
+	return nil
+}
+
+func (a EdgeAttrs) Equal(b EdgeAttrs) bool {
+	sameFloat := func(x, y *float64) bool { return (x == nil && y == nil) || (x != nil && y != nil && *x == *y) }
+	sameInt := func(x, y *int) bool { return (x == nil && y == nil) || (x != nil && y != nil && *x == *y) }
+	return sameFloat(a.Kappa, b.Kappa) && sameInt(a.Threshold, b.Threshold) && a.Cost == b.Cost && a.MaxRate == b.MaxRate
+}
+
+// Cost of the edge, 1 when unset
+func (a EdgeAttrs) CostOrDefault() float64 {
+	if a.Cost == 0 {
+		return 1
+	}
+	return a.Cost
+}
+
+// Topology maps node IDs to clusters
+type Topology map[string]Node
+
+// Default ports filled into addresses without a port
+type Ports struct {
+	HTTP    int
+	GRPC    int
+	Backend int
+}
+
+// Neighbor is an adjacent LB resolved from the view of one node
+type Neighbor struct {
+	ID   string
+	HTTP string // host:port receiving forwarded requests
+	GRPC string // host:port of the feedback stream
+	EdgeAttrs
+}
+
+// Backend is a web server inside a cluster
+type Backend struct {
+	ID   string
+	Addr string // host:port
+}
+
+func Load(path string) (Topology, error) {
+	value, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return Parse(value)
+}
+
+func Parse(value []byte) (Topology, error) {
+	topo := make(Topology)
+	if err := json.Unmarshal(value, &topo); err != nil {
+		return nil, fmt.Errorf("failed to parse adjacency list: %v", err)
+	}
+	return topo, nil
+}
+
+// IDs returns the node IDs in natural order (cluster2 < cluster10)
+func (t Topology) IDs() []string {
+	ids := make([]string, 0, len(t))
+	for id := range t {
+		ids = append(ids, id)
+	}
+	SortIDs(ids)
+	return ids
+}
+
+// Address of the LB of node id receiving requests
+func (t Topology) HTTPAddr(id string, ports Ports) (string, error) {
+	node, ok := t[id]
+	if !ok {
+		return "", fmt.Errorf("node %q is not in the adjacency list", id)
+	}
+	addr, ok := node.InternalList[LBKey]
+	if !ok {
+		return "", fmt.Errorf("node %q has no internalList.%s", id, LBKey)
+	}
+	return WithDefaultPort(addr, ports.HTTP)
+}
+
+// Address of the LB of node id serving the feedback stream
+func (t Topology) GRPCAddr(id string, ports Ports) (string, error) {
+	node, ok := t[id]
+	if !ok {
+		return "", fmt.Errorf("node %q is not in the adjacency list", id)
+	}
+	if node.GRPC != "" {
+		return WithDefaultPort(node.GRPC, ports.GRPC)
+	}
+	addr, err := t.HTTPAddr(id, ports)
+	if err != nil {
+		return "", err
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	return net.JoinHostPort(host, strconv.Itoa(ports.GRPC)), nil
+}
+
+// Neighbors of node id sorted by node ID
+func (t Topology) Neighbors(id string, ports Ports) ([]Neighbor, error) {
+	node, ok := t[id]
+	if !ok {
+		return nil, fmt.Errorf("node %q is not in the adjacency list", id)
+	}
+
+	var neighbors []Neighbor
+	for nid, edge := range node.AdjacentList {
+		httpAddr, err := WithDefaultPort(edge.Address, ports.HTTP)
+		if err != nil {
+			return nil, fmt.Errorf("node %q: neighbor %q: %v", id, nid, err)
+		}
+
+		// The neighbor's own entry decides its gRPC port when it is listed
+		grpcAddr := ""
+		if other, ok := t[nid]; ok && other.GRPC != "" {
+			grpcAddr, err = WithDefaultPort(other.GRPC, ports.GRPC)
+			if err != nil {
+				return nil, fmt.Errorf("node %q: grpc: %v", nid, err)
+			}
+		} else {
+			host, _, _ := net.SplitHostPort(httpAddr)
+			grpcAddr = net.JoinHostPort(host, strconv.Itoa(ports.GRPC))
+		}
+
+		neighbors = append(neighbors, Neighbor{ID: nid, HTTP: httpAddr, GRPC: grpcAddr, EdgeAttrs: edge.EdgeAttrs})
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		return LessID(neighbors[i].ID, neighbors[j].ID)
+	})
+	return neighbors, nil
+}
+
+// Web servers of node id sorted by name
+func (t Topology) Backends(id string, ports Ports) ([]Backend, error) {
+	node, ok := t[id]
+	if !ok {
+		return nil, fmt.Errorf("node %q is not in the adjacency list", id)
+	}
+
+	var backends []Backend
+	for k, addr := range node.InternalList {
+		if !strings.HasPrefix(k, "web") {
+			continue
+		}
+		a, err := WithDefaultPort(addr, ports.Backend)
+		if err != nil {
+			return nil, fmt.Errorf("node %q: %s: %v", id, k, err)
+		}
+		backends = append(backends, Backend{ID: k, Addr: a})
+	}
+	sort.Slice(backends, func(i, j int) bool {
+		return LessID(backends[i].ID, backends[j].ID)
+	})
+	return backends, nil
+}
+
+// WithDefaultPort returns addr as host:port, adding port when addr has none.
+// Bare IPv6 addresses are accepted with or without brackets.
+func WithDefaultPort(addr string, port int) (string, error) {
+	if addr == "" {
+		return "", fmt.Errorf("empty address")
+	}
+	if ip := net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")); ip != nil {
+		return net.JoinHostPort(ip.String(), strconv.Itoa(port)), nil
+	}
+	if host, p, err := net.SplitHostPort(addr); err == nil {
+		if host == "" {
+			return "", fmt.Errorf("address %q has no host", addr)
+		}
+		if n, err := strconv.Atoi(p); err != nil || n < 1 || n > 65535 {
+			return "", fmt.Errorf("address %q has an invalid port", addr)
+		}
+		return addr, nil
+	}
+	if strings.ContainsAny(addr, ":[]") {
+		return "", fmt.Errorf("invalid address %q (use host, host:port or [ipv6]:port)", addr)
+	}
+	return net.JoinHostPort(addr, strconv.Itoa(port)), nil
+}
+
+// SortIDs sorts node IDs in natural order
+func SortIDs(ids []string) {
+	sort.Slice(ids, func(i, j int) bool { return LessID(ids[i], ids[j]) })
+}
+
+// LessID compares node IDs by their prefix and then by the number at their end,
+// so that cluster2 comes before cluster10
+func LessID(a, b string) bool {
+	pa, na, okA := splitNumber(a)
+	pb, nb, okB := splitNumber(b)
+	if okA && okB && pa == pb {
+		if na != nb {
+			return na < nb
+		}
+	}
+	return a < b
+}
+
+func splitNumber(id string) (string, int, bool) {
+	i := len(id)
+	for i > 0 && id[i-1] >= '0' && id[i-1] <= '9' {
+		i--
+	}
+	if i == len(id) {
+		return id, 0, false
+	}
+	n, err := strconv.Atoi(id[i:])
+	if err != nil {
+		return id, 0, false
+	}
+	return id[:i], n, true
+}
@@ -0,0 +1,203 @@
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Report lists the problems found in a topology.
+// Errors make the LB refuse to start; warnings are only printed.
+type Report struct {
+	Errors   []string
+	Warnings []string
+}
+
+func (r *Report) errorf(format string, a ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, a...))
+}
+
+func (r *Report) warnf(format string, a ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, a...))
+}
+
+// Err returns all errors as one error, or nil
+func (r *Report) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return errors.New("invalid topology:\n  - " + strings.Join(r.Errors, "\n  - "))
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, e := range r.Errors {
+		fmt.Fprintf(&b, "error: %s\n", e)
+	}
+	for _, w := range r.Warnings {
+		fmt.Fprintf(&b, "warning: %s\n", w)
+	}
+	return b.String()
+}
+
+// Check validates the topology as a whole. required lists node IDs that
+// must be present (e.g. the own node and the leader).
+func Check(t Topology, ports Ports, required ...string) *Report {
+	r := &Report{}
+
+	if len(t) == 0 {
+		r.errorf("adjacency list has no nodes")
+		return r
+	}
+
+	for _, id := range required {
+		if _, ok := t[id]; !ok {
+			r.errorf("node %q is not in the adjacency list (available: %s)", id, strings.Join(t.IDs(), ", "))
+		}
+	}
+
+	ids := t.IDs()
+	lbOwner := make(map[string]string)
+	grpcOwner := make(map[string]string)
+	webOwner := make(map[string]string)
+
+	for _, id := range ids {
+		node := t[id]
+
+		// Addresses of the node itself
+		httpAddr, err := t.HTTPAddr(id, ports)
+		if err != nil {
+			r.errorf("%v", err)
+		} else {
+			if other, ok := lbOwner[httpAddr]; ok {
+				r.errorf("nodes %q and %q have the same cluster_lb address %s", other, id, httpAddr)
+			}
+			lbOwner[httpAddr] = id
+		}
+		if grpcAddr, err := t.GRPCAddr(id, ports); err == nil {
+			if other, ok := grpcOwner[grpcAddr]; ok {
+				r.errorf("nodes %q and %q have the same gRPC address %s", other, id, grpcAddr)
+			}
+			grpcOwner[grpcAddr] = id
+		} else if httpAddr != "" {
+			r.errorf("%v", err)
+		}
+
+		// Web servers
+		backends, err := t.Backends(id, ports)
+		if err != nil {
+			r.errorf("%v", err)
+		} else if len(backends) == 0 {
+			r.errorf("node %q has no web servers (web* in internalList)", id)
+		}
+		for _, b := range backends {
+			if other, ok := webOwner[b.Addr]; ok {
+				r.errorf("web server %s is listed by both %s and %s.%s", b.Addr, other, id, b.ID)
+			}
+			webOwner[b.Addr] = id + "." + b.ID
+		}
+
+		// Adjacency
+		if len(node.AdjacentList) == 0 && len(t) > 1 {
+			r.errorf("node %q has no neighbors", id)
+		}
+		for _, nid := range sortedKeys(node.AdjacentList) {
+			edge := node.AdjacentList[nid]
+			if nid == id {
+				r.errorf("node %q lists itself as a neighbor", id)
+				continue
+			}
+			other, ok := t[nid]
+			if !ok {
+				r.errorf("node %q lists unknown neighbor %q", id, nid)
+				continue
+			}
+			if back, ok := other.AdjacentList[id]; !ok {
+				r.errorf("adjacency is not symmetric: %q lists %q but %q does not list %q", id, nid, nid, id)
+			} else if LessID(id, nid) && !edge.EdgeAttrs.Equal(back.EdgeAttrs) {
+				r.warnf("edge %s-%s has different attributes in each direction", id, nid)
+			}
+			if err := edge.EdgeAttrs.Validate(); err != nil {
+				r.errorf("edge %s->%s: %v", id, nid, err)
+			}
+
+			neighborAddr, err := WithDefaultPort(edge.Address, ports.HTTP)
+			if err != nil {
+				r.errorf("node %q: neighbor %q: %v", id, nid, err)
+				continue
+			}
+			if ownAddr, err := t.HTTPAddr(nid, ports); err == nil && !sameAddr(neighborAddr, ownAddr) {
+				r.warnf("node %q reaches %q at %s, but %q has cluster_lb %s", id, nid, neighborAddr, nid, ownAddr)
+			}
+		}
+	}
+
+	if components := t.Components(); len(components) > 1 {
+		parts := make([]string, len(components))
+		for i, c := range components {
+			parts[i] = "{" + strings.Join(c, ", ") + "}"
+		}
+		r.errorf("graph is not connected: %s", strings.Join(parts, " "))
+	}
+	if len(t) == 1 {
+		r.warnf("adjacency list has a single node, so nothing is forwarded")
+	}
+
+	return r
+}
+
+// Components returns the connected components of the graph (each sorted),
+// treating every listed edge as undirected
+func (t Topology) Components() [][]string {
+	adjacent := make(map[string][]string)
+	for id, node := range t {
+		for nid := range node.AdjacentList {
+			if _, ok := t[nid]; !ok || nid == id {
+				continue
+			}
+			adjacent[id] = append(adjacent[id], nid)
+			adjacent[nid] = append(adjacent[nid], id)
+		}
+	}
+
+	visited := make(map[string]bool)
+	var components [][]string
+	for _, start := range t.IDs() {
+		if visited[start] {
+			continue
+		}
+		var component []string
+		stack := []string{start}
+		visited[start] = true
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			component = append(component, id)
+			for _, nid := range adjacent[id] {
+				if !visited[nid] {
+					visited[nid] = true
+					stack = append(stack, nid)
+				}
+			}
+		}
+		SortIDs(component)
+		components = append(components, component)
+	}
+	return components
+}
+
+func sameAddr(a, b string) bool {
+	ha, pa, _ := net.SplitHostPort(a)
+	hb, pb, _ := net.SplitHostPort(b)
+	return strings.EqualFold(ha, hb) && pa == pb
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	SortIDs(keys)
+	return keys
+}
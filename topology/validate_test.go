@@ -0,0 +1,149 @@
+package topology
+
+import (
+	"strings"
+	"testing"
+)
+
+var testPorts = Ports{HTTP: 8001, GRPC: 50051, Backend: 80}
+
+// Two clusters that pass the check without warnings
+const pairJSON = `{
+  "cluster0": {"adjacentList": {"cluster1": "10.0.0.2"}, "internalList": {"cluster_lb": "10.0.0.1", "web0": "10.0.1.1"}},
+  "cluster1": {"adjacentList": {"cluster0": "10.0.0.1"}, "internalList": {"cluster_lb": "10.0.0.2", "web0": "10.0.2.1"}}
+}`
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		required []string
+		errors   []string // substrings, one per expected error
+		warnings []string // substrings, one per expected warning
+	}{
+		{
+			name:     "valid",
+			json:     pairJSON,
+			required: []string{"cluster0", "cluster1"},
+		},
+		{
+			name:   "empty",
+			json:   `{}`,
+			errors: []string{"adjacency list has no nodes"},
+		},
+		{
+			name:     "missing required node",
+			json:     pairJSON,
+			required: []string{"cluster0", "cluster5"},
+			errors:   []string{`node "cluster5" is not in the adjacency list (available: cluster0, cluster1)`},
+		},
+		{
+			name: "asymmetric edge",
+			json: `{
+  "cluster0": {"adjacentList": {"cluster1": "10.0.0.2"}, "internalList": {"cluster_lb": "10.0.0.1", "web0": "10.0.1.1"}},
+  "cluster1": {"adjacentList": {"cluster2": "10.0.0.3"}, "internalList": {"cluster_lb": "10.0.0.2", "web0": "10.0.2.1"}},
+  "cluster2": {"adjacentList": {"cluster1": "10.0.0.2"}, "internalList": {"cluster_lb": "10.0.0.3", "web0": "10.0.3.1"}}
+}`,
+			errors: []string{`adjacency is not symmetric: "cluster0" lists "cluster1" but "cluster1" does not list "cluster0"`},
+		},
+		{
+			name: "unknown neighbor",
+			json: `{
+  "cluster0": {"adjacentList": {"cluster1": "10.0.0.2", "cluster9": "10.0.0.9"}, "internalList": {"cluster_lb": "10.0.0.1", "web0": "10.0.1.1"}},
+  "cluster1": {"adjacentList": {"cluster0": "10.0.0.1"}, "internalList": {"cluster_lb": "10.0.0.2", "web0": "10.0.2.1"}}
+}`,
+			errors: []string{`node "cluster0" lists unknown neighbor "cluster9"`},
+		},
+		{
+			name: "bad ports",
+			json: `{
+  "cluster0": {"adjacentList": {"cluster1": "10.0.0.2:0"}, "internalList": {"cluster_lb": "10.0.0.1:70000", "web0": "10.0.1.1:http"}},
+  "cluster1": {"adjacentList": {"cluster0": "10.0.0.1"}, "internalList": {"cluster_lb": "10.0.0.2", "web0": "10.0.2.1"}}
+}`,
+			errors: []string{
+				`address "10.0.0.1:70000" has an invalid port`,
+				`node "cluster0": web0: address "10.0.1.1:http" has an invalid port`,
+				`node "cluster0": neighbor "cluster1": address "10.0.0.2:0" has an invalid port`,
+			},
+		},
+		{
+			name: "shared addresses",
+			json: `{
+  "cluster0": {"adjacentList": {"cluster1": "10.0.0.1:8002"}, "internalList": {"cluster_lb": "10.0.0.1", "web0": "10.0.1.1"}},
+  "cluster1": {"adjacentList": {"cluster0": "10.0.0.1"}, "internalList": {"cluster_lb": "10.0.0.1:8002", "web0": "10.0.1.1"}}
+}`,
+			errors: []string{
+				`nodes "cluster0" and "cluster1" have the same gRPC address 10.0.0.1:50051`,
+				"web server 10.0.1.1:80 is listed by both cluster0.web0 and cluster1.web0",
+			},
+		},
+		{
+			name: "disconnected",
+			json: `{
+  "cluster0": {"adjacentList": {"cluster1": "10.0.0.2"}, "internalList": {"cluster_lb": "10.0.0.1", "web0": "10.0.1.1"}},
+  "cluster1": {"adjacentList": {"cluster0": "10.0.0.1"}, "internalList": {"cluster_lb": "10.0.0.2", "web0": "10.0.2.1"}},
+  "cluster2": {"adjacentList": {"cluster3": "10.0.0.4"}, "internalList": {"cluster_lb": "10.0.0.3", "web0": "10.0.3.1"}},
+  "cluster3": {"adjacentList": {"cluster2": "10.0.0.3"}, "internalList": {"cluster_lb": "10.0.0.4", "web0": "10.0.4.1"}}
+}`,
+			errors: []string{"graph is not connected: {cluster0, cluster1} {cluster2, cluster3}"},
+		},
+		{
+			name: "attributes differ by direction",
+			json: `{
+  "cluster0": {"adjacentList": {"cluster1": {"address": "10.0.0.2", "kappa": 0.2}}, "internalList": {"cluster_lb": "10.0.0.1", "web0": "10.0.1.1"}},
+  "cluster1": {"adjacentList": {"cluster0": {"address": "10.0.0.1", "kappa": 0.3}}, "internalList": {"cluster_lb": "10.0.0.2", "web0": "10.0.2.1"}}
+}`,
+			warnings: []string{"edge cluster0-cluster1 has different attributes in each direction"},
+		},
+		{
+			name: "neighbor address differs from cluster_lb",
+			json: `{
+  "cluster0": {"adjacentList": {"cluster1": "10.0.0.2:9000"}, "internalList": {"cluster_lb": "10.0.0.1", "web0": "10.0.1.1"}},
+  "cluster1": {"adjacentList": {"cluster0": "10.0.0.1"}, "internalList": {"cluster_lb": "10.0.0.2", "web0": "10.0.2.1"}}
+}`,
+			warnings: []string{`node "cluster0" reaches "cluster1" at 10.0.0.2:9000, but "cluster1" has cluster_lb 10.0.0.2:8001`},
+		},
+		{
+			name:     "single node",
+			json:     `{"cluster0": {"adjacentList": {}, "internalList": {"cluster_lb": "10.0.0.1", "web0": "10.0.1.1"}}}`,
+			warnings: []string{"adjacency list has a single node, so nothing is forwarded"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topo, err := Parse([]byte(tt.json))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			r := Check(topo, testPorts, tt.required...)
+			matchAll(t, "error", r.Errors, tt.errors)
+			matchAll(t, "warning", r.Warnings, tt.warnings)
+			if (r.Err() == nil) != (len(tt.errors) == 0) {
+				t.Errorf("Err() = %v with %d errors", r.Err(), len(r.Errors))
+			}
+		})
+	}
+}
+
+// Each of got must contain one of want, in any order
+func matchAll(t *testing.T, kind string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("got %d %ss %q, want %d", len(got), kind, got, len(want))
+		return
+	}
+	used := make([]bool, len(want))
+	for _, g := range got {
+		found := false
+		for i, w := range want {
+			if !used[i] && strings.Contains(g, w) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("unexpected %s %q", kind, g)
+		}
+	}
+}